@@ -8,7 +8,7 @@ import (
 func createLayer() wind.Layer {
 	char := wind.CharBlock
 	border := func(layer wind.Layer) wind.Layer {
-		return wind.Border('─', '│', layer)
+		return wind.Frame(wind.SingleBorder, layer)
 	}
 	aaa := border(char('a'))
 	bbb := border(char('v'))
@@ -39,23 +39,5 @@ func createLayer() wind.Layer {
 }
 
 func main() {
-	term.Init()
-
-	canvas := wind.NewTermCanvas()
-	layer := createLayer()
-
-	for {
-		layer.Render(canvas)
-		term.Flush()
-
-		e := term.PollEvent()
-		if e.Ch == 'q' || e.Key == term.KeyCtrlC {
-			break
-		} else if e.Type == term.EventResize {
-			term.Clear(0, 0)
-			wind.ClearCache(layer)
-		}
-	}
-
-	term.Close()
+	wind.NewApp(createLayer()).Run()
 }
@@ -10,45 +10,25 @@ var tabElem2 = wind.SetColor(uint16(term.ColorBlue), 0, wind.CharBlock('2'))
 var tabElem3 = wind.SetColor(uint16(term.ColorGreen), 0, wind.CharBlock('3'))
 
 func main() {
-	term.Init()
-
-	// TODO: show a tab title that changes with the content
-
-	canvas := wind.NewTermCanvas()
 	tab := wind.Tab()
+	keys := wind.NewKeymap().
+		MustBind("1", "ones", func() { tab.ShowName("ones") }).
+		MustBind("2", "twos", func() { tab.ShowName("twos") }).
+		MustBind("3", "threes", func() { tab.ShowIndex(2) }).
+		MustBind("Right", "next", func() { tab.Next() }).
+		MustBind("Left", "previous", func() { tab.Prev() })
+
 	layer := wind.Vlayer(
 		wind.Text("Tabbed | Press keys 1, 2 or 3 to switch tab"),
+		wind.TabBar(tab),
 		wind.LineH('─'),
 		tab.SetElements(
 			tab.Name("ones", tabElem1),
 			tab.Name("twos", tabElem2),
 			tabElem3, // allows optional naming
 		),
+		keys.Help(),
 	)
 
-	for {
-		layer.Render(canvas)
-		term.Flush()
-		e := term.PollEvent()
-		if e.Key == 0 {
-			switch e.Ch {
-			case '1':
-				tab.ShowName("ones")
-			case '2':
-				tab.ShowName("twos")
-			case '3':
-				tab.ShowIndex(2)
-			case 'q':
-				goto exit
-			}
-		} else {
-			switch e.Key {
-			case term.KeyCtrlC:
-				goto exit
-			}
-		}
-	}
-
-exit:
-	term.Close()
+	wind.NewApp(layer).SetKeymap(keys).EnableMouse().Run()
 }
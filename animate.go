@@ -0,0 +1,280 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Scheduler collects requests for the next render. Layers that
+// animate ask for a redraw while they render, with RequestRedraw,
+// and ask again on the next render as long as they keep moving.
+// So when nothing animates, nothing is requested and the render
+// loop waits for input.
+//
+// A request can be for the whole tree, or for some layers only,
+// which are then drawn again in their place, see HitMap.Update.
+type Scheduler struct {
+	mu      sync.Mutex
+	next    time.Time
+	pending bool
+	wake    chan struct{}
+
+	// caches to clear before the next render
+	invalid []*cacheLayer
+
+	// layers to draw again, or all of them
+	dirty []Layer
+	all   bool
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{wake: make(chan struct{}, 1)}
+}
+
+// RequestRedraw asks for a render of the whole tree after delay.
+// The earliest request wins. It is safe to call from any goroutine.
+func (s *Scheduler) RequestRedraw(delay time.Duration) {
+	s.request(nil, delay)
+}
+
+// Invalidate asks for layer to be drawn again right away, without
+// the rest of the tree. It is safe to call from any goroutine.
+func (s *Scheduler) Invalidate(layer Layer) {
+	s.request(layer, 0)
+}
+
+// request adds layer, or the whole tree for nil, to the next render
+func (s *Scheduler) request(layer Layer, delay time.Duration) {
+	at := time.Now().Add(delay)
+	s.mu.Lock()
+	if layer == nil {
+		s.all = true
+	} else {
+		s.dirty = append(s.dirty, layer)
+	}
+	earlier := !s.pending || at.Before(s.next)
+	if earlier {
+		s.next, s.pending = at, true
+	}
+	s.mu.Unlock()
+	if earlier {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Next is when the requested render is due.
+func (s *Scheduler) Next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next, s.pending
+}
+
+// Expire drops the request if it is due at now,
+// and returns whether it was.
+func (s *Scheduler) Expire(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.pending || now.Before(s.next) {
+		return false
+	}
+	s.pending = false
+	return true
+}
+
+// invalidate has the caches cleared before the next render
+func (s *Scheduler) invalidate(caches []*cacheLayer) {
+	s.mu.Lock()
+	s.invalid = append(s.invalid, caches...)
+	s.mu.Unlock()
+}
+
+// Flush clears the layout caches that changed states have
+// invalidated. App does this before each render; a caller that
+// renders on its own should too, on the goroutine that renders.
+func (s *Scheduler) Flush() {
+	s.mu.Lock()
+	invalid := s.invalid
+	s.invalid = nil
+	s.mu.Unlock()
+	for _, cache := range invalid {
+		cache.invalidate()
+	}
+}
+
+// TakeDirty returns the layers to draw again since the last call,
+// or all as true when the whole tree is to be rendered.
+func (s *Scheduler) TakeDirty() (layers []Layer, all bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	layers, all = s.dirty, s.all
+	s.dirty, s.all = nil, false
+	return layers, all
+}
+
+// Wake receives when a request comes in that
+// is earlier than the one that was pending.
+func (s *Scheduler) Wake() <-chan struct{} { return s.wake }
+
+type schedulerCanvas struct {
+	Canvas
+	scheduler *Scheduler
+}
+
+func (canvas *schedulerCanvas) unwrap() Canvas { return canvas.Canvas }
+
+func (canvas *schedulerCanvas) New(x, y, width, height int) Canvas {
+	return &schedulerCanvas{canvas.Canvas.New(x, y, width, height), canvas.scheduler}
+}
+
+// WithScheduler returns canvas with the scheduler that the
+// layers rendered on it give their redraw requests to.
+// App does this for the layers it runs.
+func WithScheduler(canvas Canvas, scheduler *Scheduler) Canvas {
+	return &schedulerCanvas{canvas, scheduler}
+}
+
+// RequestRedraw asks the scheduler of canvas for a render after
+// delay, of the layer being rendered on canvas. Without a scheduler,
+// as when the caller renders on its own, the request is dropped.
+func RequestRedraw(canvas Canvas, delay time.Duration) {
+	c, ok := findCanvas[*schedulerCanvas](canvas)
+	if !ok {
+		return
+	}
+	var layer Layer
+	if hits := hitMapOf(canvas); hits != nil {
+		layer = hits.current()
+	}
+	c.scheduler.request(layer, delay)
+}
+
+// untilNext returns how long until the next multiple
+// of interval since start, for staying on the beat
+func untilNext(start time.Time, interval time.Duration) time.Duration {
+	return interval - time.Since(start)%interval
+}
+
+var DefaultSpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// SpinnerLayer cycles through frames while it runs,
+// followed by a label. A stopped spinner shows only the label.
+type SpinnerLayer struct {
+	frames   []string
+	interval time.Duration
+	label    string
+	start    time.Time
+	running  bool
+}
+
+func Spinner(label string) *SpinnerLayer {
+	return &SpinnerLayer{
+		frames:   DefaultSpinnerFrames,
+		interval: 80 * time.Millisecond,
+		label:    label,
+		start:    time.Now(),
+		running:  true,
+	}
+}
+
+func (s *SpinnerLayer) SetFrames(frames ...string) *SpinnerLayer {
+	s.frames = frames
+	return s
+}
+
+func (s *SpinnerLayer) SetInterval(interval time.Duration) *SpinnerLayer {
+	s.interval = interval
+	return s
+}
+
+func (s *SpinnerLayer) SetLabel(label string) *SpinnerLayer {
+	s.label = label
+	return s
+}
+
+func (s *SpinnerLayer) Start() *SpinnerLayer {
+	if !s.running {
+		s.start = time.Now()
+		s.running = true
+	}
+	return s
+}
+
+func (s *SpinnerLayer) Stop() *SpinnerLayer {
+	s.running = false
+	return s
+}
+
+func (s *SpinnerLayer) Running() bool { return s.running }
+
+func (s *SpinnerLayer) frame() string {
+	if !s.running || len(s.frames) == 0 || s.interval <= 0 {
+		return ""
+	}
+	i := int(time.Since(s.start) / s.interval)
+	return s.frames[i%len(s.frames)]
+}
+
+func (s *SpinnerLayer) Width() size.T {
+	w := 0
+	for _, frame := range s.frames {
+		w = max(w, utf8.RuneCountInString(frame))
+	}
+	return size.Const(w + 1 + utf8.RuneCountInString(s.label))
+}
+
+func (s *SpinnerLayer) Height() size.T { return size.Const(1) }
+
+func (s *SpinnerLayer) Render(canvas Canvas) {
+	frame := s.frame()
+	canvas.DrawText(0, 0, frame, 0, 0)
+	x := 0
+	if frame != "" {
+		x = utf8.RuneCountInString(frame) + 1
+	}
+	canvas.DrawText(x, 0, s.label, 0, 0)
+	if frame != "" {
+		RequestRedraw(canvas, untilNext(s.start, s.interval))
+	}
+}
+
+// Blink shows layer and hides it in turns, each for interval.
+func Blink(interval time.Duration, layer Layer) Layer {
+	return &blinkLayer{layer, interval, time.Now()}
+}
+
+// BlinkingCursor is a one cell block that blinks twice a second.
+func BlinkingCursor() Layer {
+	return Blink(500*time.Millisecond, Size(1, 1, RenderLayer(func(canvas Canvas) {
+		canvas.Draw(0, 0, ' ', uint16(term.AttrReverse), 0)
+	})))
+}
+
+type blinkLayer struct {
+	layer    Layer
+	interval time.Duration
+	start    time.Time
+}
+
+func (b *blinkLayer) Width() size.T  { return b.layer.Width() }
+func (b *blinkLayer) Height() size.T { return b.layer.Height() }
+
+func (b *blinkLayer) Children() []Layer { return []Layer{b.layer} }
+
+func (b *blinkLayer) HeightFor(width int) (int, bool) { return heightFor(b.layer, width) }
+
+func (b *blinkLayer) Render(canvas Canvas) {
+	if b.interval <= 0 {
+		RenderChild(b.layer, canvas)
+		return
+	}
+	if time.Since(b.start)/b.interval%2 == 0 {
+		RenderChild(b.layer, canvas)
+	}
+	RequestRedraw(canvas, untilNext(b.start, b.interval))
+}
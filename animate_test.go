@@ -0,0 +1,87 @@
+package wind
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedulerRequests(t *testing.T) {
+	s := NewScheduler()
+	if _, ok := s.Next(); ok {
+		t.Fatal("nothing requested yet")
+	}
+	s.RequestRedraw(time.Hour)
+	s.RequestRedraw(time.Minute)
+	s.RequestRedraw(time.Hour)
+	next, ok := s.Next()
+	if !ok || time.Until(next) > time.Minute {
+		t.Errorf("the earliest request should win")
+	}
+	select {
+	case <-s.Wake():
+	default:
+		t.Error("a request should wake the loop")
+	}
+	if s.Expire(time.Now()) {
+		t.Error("the request is not due yet")
+	}
+	if !s.Expire(next) {
+		t.Error("the request should be due")
+	}
+	if _, ok := s.Next(); ok {
+		t.Error("a due request should be dropped")
+	}
+}
+
+func TestSpinnerRequestsRedraw(t *testing.T) {
+	s := NewScheduler()
+	spinner := Spinner("loading").SetFrames("-", "+").SetInterval(time.Hour)
+	canvas := NewStringCanvas(10, 1)
+	RenderChild(spinner, WithScheduler(canvas, s))
+	if got := canvas.String(); got != "- loading \n" {
+		t.Errorf("render: %q", got)
+	}
+	if _, ok := s.Next(); !ok {
+		t.Error("a running spinner should ask for a redraw")
+	}
+
+	s.Expire(time.Now().Add(2 * time.Hour))
+	spinner.Stop()
+	canvas = NewStringCanvas(10, 1)
+	RenderChild(spinner, WithScheduler(canvas, s))
+	if got := canvas.String(); got != "loading   \n" {
+		t.Errorf("stopped: %q", got)
+	}
+	if _, ok := s.Next(); ok {
+		t.Error("a stopped spinner should not ask for redraws")
+	}
+}
+
+func TestBlink(t *testing.T) {
+	s := NewScheduler()
+	canvas := NewStringCanvas(1, 1)
+	RenderChild(Blink(time.Hour, Text("x")), WithScheduler(canvas, s))
+	if canvas.String() != "x\n" {
+		t.Errorf("blink should start shown")
+	}
+	next, ok := s.Next()
+	if !ok || time.Until(next) > time.Hour {
+		t.Errorf("blink should ask for a redraw when it changes")
+	}
+}
+
+func TestRequestRedrawMarksLayer(t *testing.T) {
+	s := NewScheduler()
+	spinner := Spinner("").SetInterval(time.Hour)
+	hits := NewHitMap()
+	hits.Render(Vlayer(spinner, Text("x")), WithScheduler(NewStringCanvas(3, 2), s))
+	layers, all := s.TakeDirty()
+	if all || len(layers) != 1 || layers[0] != Layer(spinner) {
+		t.Errorf("only the spinner should be drawn again: %v %v", layers, all)
+	}
+
+	s.RequestRedraw(0)
+	if _, all := s.TakeDirty(); !all {
+		t.Errorf("a request of the scheduler is for the whole tree")
+	}
+}
@@ -57,12 +57,54 @@ type Layer interface {
 	Render(canvas Canvas)
 }
 
+// Container is a layer with other layers in it. All the layers of
+// this package that have layers in them are containers, so walks
+// over a layer tree, like ClearCache and the focus traversal, go
+// through any of them.
+type Container interface {
+	Layer
+	// Children returns the layers directly in it, shown or not.
+	Children() []Layer
+}
+
+// Switcher is a container that shows only some of its children at
+// a time, like a tab layer, or an overlay with a popup open. The
+// focus traversal only goes into the shown children.
+type Switcher interface {
+	Container
+	Shown() []Layer
+}
+
+// HeightForWidth is a layer whose height depends on the width it
+// gets, as Markdown, whose paragraphs wrap. Scroll asks it how tall
+// its content is. The layers that wrap one layer, like SetColor,
+// Border and Frame, pass the question on.
+type HeightForWidth interface {
+	Layer
+	// HeightFor returns the height the layer takes at width,
+	// or false when it does not know
+	HeightFor(width int) (int, bool)
+}
+
+// heightFor asks layer, if it is a HeightForWidth
+func heightFor(layer Layer, width int) (int, bool) {
+	if hw, ok := layer.(HeightForWidth); ok {
+		return hw.HeightFor(width)
+	}
+	return 0, false
+}
+
 type TabLayer interface {
 	Layer
 	Name(name string, elem Layer) Layer
 	SetElements(elements ...Layer) TabLayer
+	AddTab(title string, elem Layer) TabLayer
+	Titles() []string
+	Index() int
 	ShowName(name string) TabLayer
 	ShowIndex(index int) TabLayer
+	Next() TabLayer
+	Prev() TabLayer
 	Hide() TabLayer
 }
 
@@ -153,19 +195,23 @@ func Zlayer(elements ...Layer) Layer {
 }
 
 func NoExpand(layer Layer) Layer {
-	return &aligner{layer, false, false}
+	return &aligner{layer, false, false, false}
 }
 
 func AlignRight(layer Layer) Layer {
-	return &aligner{layer, true, false}
+	return &aligner{layer, true, false, false}
 }
 
 func AlignDown(layer Layer) Layer {
-	return &aligner{layer, false, true}
+	return &aligner{layer, false, true, false}
 }
 
 func AlignDownRight(layer Layer) Layer {
-	return &aligner{layer, true, true}
+	return &aligner{layer, true, true, false}
+}
+
+func AlignCenter(layer Layer) Layer {
+	return &aligner{layer, false, false, true}
 }
 
 // TODO: rename to FreeSize
@@ -215,7 +261,7 @@ func TapRender(layer Layer, render func(layer Layer, canvas Canvas)) Layer {
 func SetColor(fg, bg uint16, layer Layer) Layer {
 	return TapRender(layer, func(layer Layer, canvas Canvas) {
 		canvas = ChangeDefaultColor(fg, bg, canvas)
-		layer.Render(canvas)
+		RenderChild(layer, canvas)
 	})
 }
 
@@ -242,7 +288,7 @@ func Either(left Defer, right Layer) Layer {
 		if layer == nil {
 			layer = right
 		}
-		layer.Render(canvas)
+		RenderChild(layer, canvas)
 	})
 }
 
@@ -252,9 +298,18 @@ func Tab() TabLayer {
 	}
 }
 
+// ClearCache drops the cached sizes of every Hlayer, Vlayer and
+// Zlayer in layer, through all the layers they are in, shown or not.
 func ClearCache(layer Layer) {
 	if cache, ok := layer.(*cacheLayer); ok {
-		cache.clear()
+		cache.invalidate()
+	}
+	if container, ok := layer.(Container); ok {
+		for _, child := range container.Children() {
+			if child != nil {
+				ClearCache(child)
+			}
+		}
 	}
 }
 
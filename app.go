@@ -0,0 +1,276 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"sync"
+	"time"
+)
+
+// App runs a layer on the terminal. It owns the termbox session,
+// renders the root layer after every event, clears the layout caches
+// when the terminal is resized, and routes keys through a Focus and
+// mouse events through a HitMap.
+//
+// Layers that animate ask for renders through the Scheduler,
+// see RequestRedraw. Those renders draw only the layers that
+// asked, when they can, see HitMap.Update.
+//
+// Key events that no layer and no OnKey handler took quit the
+// app when they are Ctrl-C or 'q'.
+type App struct {
+	root   Layer
+	canvas Canvas
+	focus  *Focus
+	hits   *HitMap
+	keymap *Keymap
+	sched  *Scheduler
+
+	keyHandlers  []func(e term.Event) bool
+	quitHandlers []func()
+	tickInterval time.Duration
+	tickHandler  func()
+	mouse        bool
+
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+func NewApp(root Layer) *App {
+	return &App{
+		root:  root,
+		focus: NewFocus(root),
+		hits:  NewHitMap(),
+		sched: NewScheduler(),
+		quit:  make(chan struct{}),
+	}
+}
+
+func (app *App) Root() Layer     { return app.root }
+func (app *App) Focus() *Focus   { return app.focus }
+func (app *App) HitMap() *HitMap { return app.hits }
+func (app *App) Canvas() Canvas  { return app.canvas }
+
+// Scheduler takes the redraw requests. Use it to redraw
+// from another goroutine, after changing what is shown.
+func (app *App) Scheduler() *Scheduler { return app.sched }
+
+// SetKeymap sets the global key bindings. They get the key
+// events that were not handled inside the layer tree.
+func (app *App) SetKeymap(km *Keymap) *App {
+	app.keymap = km
+	return app
+}
+
+// OnKey adds a handler for the key events that were not
+// handled inside the layer tree or by the keymap.
+func (app *App) OnKey(handler func(e term.Event) bool) *App {
+	app.keyHandlers = append(app.keyHandlers, handler)
+	return app
+}
+
+// OnTick calls handler every interval, followed by a render.
+func (app *App) OnTick(interval time.Duration, handler func()) *App {
+	app.tickInterval = interval
+	app.tickHandler = handler
+	return app
+}
+
+// OnQuit adds a handler that runs before the terminal is closed.
+func (app *App) OnQuit(handler func()) *App {
+	app.quitHandlers = append(app.quitHandlers, handler)
+	return app
+}
+
+func (app *App) EnableMouse() *App {
+	app.mouse = true
+	return app
+}
+
+// Quit stops Run. It is safe to call from any goroutine.
+func (app *App) Quit() {
+	app.quitOnce.Do(func() {
+		close(app.quit)
+	})
+}
+
+func (app *App) Run() error {
+	if err := term.Init(); err != nil {
+		return err
+	}
+	defer term.Close()
+
+	// esc mode delivers a lone Esc, Focus
+	// finds shift-tab and alt keys in the keys
+	mode := term.InputEsc
+	if app.mouse {
+		mode |= term.InputMouse
+	}
+	term.SetInputMode(mode)
+
+	app.canvas = NewTermCanvas()
+	events := make(chan term.Event)
+	go app.pollEvents(events)
+
+	var tick <-chan time.Time
+	if app.tickInterval > 0 {
+		ticker := time.NewTicker(app.tickInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var err error
+	draw, full := true, true
+loop:
+	for {
+		if draw {
+			app.render(full)
+		}
+		draw, full = true, true
+		var expire, esc, redraw <-chan time.Time
+		if deadline, ok := app.keymapDeadline(); ok {
+			expire = time.After(time.Until(deadline))
+		}
+		if deadline, ok := app.focus.Deadline(); ok {
+			esc = time.After(time.Until(deadline))
+		}
+		if next, ok := app.sched.Next(); ok {
+			redraw = time.After(time.Until(next))
+		}
+		select {
+		case e := <-events:
+			if e.Type == term.EventError {
+				err = e.Err
+				break loop
+			}
+			app.handleEvent(e)
+		case <-tick:
+			if app.tickHandler != nil {
+				app.tickHandler()
+			}
+		case now := <-esc:
+			app.focus.expire(now, app.handleUnfocusedKey)
+		case now := <-expire:
+			for _, km := range app.keymaps() {
+				km.Expire(now)
+			}
+		case now := <-redraw:
+			draw, full = app.sched.Expire(now), false
+		case <-app.sched.Wake():
+			// only to set the timer again
+			draw = false
+		case <-app.quit:
+			break loop
+		}
+	}
+
+	app.Quit()
+	term.Interrupt()
+	for _, handler := range app.quitHandlers {
+		handler()
+	}
+	return err
+}
+
+// keymaps returns the global keymap and
+// those given to layers with WithKeymap
+func (app *App) keymaps() []*Keymap {
+	result := keymaps(app.root)
+	if app.keymap != nil {
+		result = append(result, app.keymap)
+	}
+	return result
+}
+
+// keymapDeadline returns when the first pending sequence times out
+func (app *App) keymapDeadline() (time.Time, bool) {
+	var first time.Time
+	found := false
+	for _, km := range app.keymaps() {
+		if deadline, ok := km.Deadline(); ok && (!found || deadline.Before(first)) {
+			first, found = deadline, true
+		}
+	}
+	return first, found
+}
+
+func (app *App) pollEvents(events chan<- term.Event) {
+	for {
+		e := term.PollEvent()
+		select {
+		case events <- e:
+		case <-app.quit:
+			return
+		}
+	}
+}
+
+// render renders the root layer, or when not full, only the layers
+// the scheduler has for drawing again, if they can be drawn alone
+func (app *App) render(full bool) {
+	app.sched.Flush()
+	dirty, all := app.sched.TakeDirty()
+	// a handler may have changed which layers are shown
+	focused := app.focus.Focused()
+	app.focus.Refresh()
+	full = full || all || app.focus.Focused() != focused
+	if full || !app.hits.Update(dirty) {
+		term.Clear(0, 0)
+		app.hits.Render(app.root, WithScheduler(app.canvas, app.sched))
+	}
+	term.Flush()
+
+	// the requests made while rendering are already in the timer
+	select {
+	case <-app.sched.Wake():
+	default:
+	}
+}
+
+func (app *App) handleEvent(e term.Event) {
+	switch e.Type {
+	case term.EventResize:
+		ClearCache(app.root)
+	case term.EventMouse:
+		app.handleMouse(e)
+	case term.EventKey:
+		app.handleKey(e)
+	}
+}
+
+func (app *App) handleKey(e term.Event) {
+	app.focus.handleEvent(e, app.handleUnfocusedKey)
+}
+
+// handleUnfocusedKey takes the key events
+// that were not handled inside the layer tree
+func (app *App) handleUnfocusedKey(e term.Event) bool {
+	if app.keymap != nil && app.keymap.HandleKey(e) {
+		return true
+	}
+	for _, handler := range app.keyHandlers {
+		if handler(e) {
+			return true
+		}
+	}
+	if e.Key == term.KeyCtrlC || (e.Ch == 'q' && e.Mod == 0) {
+		app.Quit()
+		return true
+	}
+	return false
+}
+
+// a click also focuses the focusable layer under it, and
+// the layers under it can move the focus, as a form does
+func (app *App) handleMouse(e term.Event) {
+	path := app.hits.HitTest(e.MouseX, e.MouseY)
+	if isMousePress(e) && e.Mod&term.ModMotion == 0 {
+		for i := len(path) - 1; i >= 0; i-- {
+			if focusable, ok := path[i].(Focusable); ok && app.focus.canFocus(focusable) {
+				app.focus.SetFocus(focusable)
+				break
+			}
+		}
+	}
+	app.hits.Dispatch(e)
+	app.focus.takeRequest(path)
+}
@@ -0,0 +1,54 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestAppKeys(t *testing.T) {
+	input := newTestFocusable("input")
+	app := NewApp(Vlayer(input, stars))
+	handled := false
+	app.OnKey(func(e term.Event) bool {
+		handled = e.Ch == 'x'
+		return handled
+	})
+
+	quit := func() bool {
+		select {
+		case <-app.quit:
+			return true
+		default:
+			return false
+		}
+	}
+	app.handleKey(term.Event{Type: term.EventKey, Ch: 'q'})
+	if quit() || string(input.keys) != "q" {
+		t.Fatalf("the focused layer should take q")
+	}
+	app.handleKey(term.Event{Type: term.EventKey, Ch: 'x'})
+	if !handled || quit() {
+		t.Errorf("app handlers should get unhandled keys")
+	}
+	app.handleKey(term.Event{Type: term.EventKey, Key: term.KeyCtrlC})
+	if !quit() {
+		t.Errorf("ctrl-c should quit")
+	}
+	app.Quit()
+}
+
+func TestAppKeymapAltBracket(t *testing.T) {
+	ran := false
+	app := NewApp(Vlayer(TextLine("text")))
+	app.SetKeymap(NewKeymap().MustBind("Alt-[", "back", func() { ran = true }))
+	app.handleKey(term.Event{Type: term.EventKey, Key: term.KeyEsc})
+	app.handleKey(term.Event{Type: term.EventKey, Ch: '['})
+	if ran {
+		t.Fatalf("alt-[ should wait for a Z")
+	}
+	app.handleKey(term.Event{Type: term.EventKey, Key: term.KeyEnter})
+	if !ran {
+		t.Errorf("the keymap should get the held alt-[")
+	}
+	app.Quit()
+}
@@ -0,0 +1,220 @@
+package wind
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Font is a parsed FIGlet (.flf) font.
+// Only full-width layout is done, smushing
+// and kerning rules in the header are ignored.
+type Font struct {
+	height    int
+	hardblank rune
+	glyphs    map[rune][][]rune
+}
+
+var DefaultFont = mustParseFont(blockFont)
+
+// FIGlet fonts must define the printable ascii characters
+// followed by these, in this order. Fonts that stop
+// after the ascii set are still accepted.
+var deutschRunes = []rune{196, 214, 220, 228, 246, 252, 223}
+
+func LoadFont(filename string) (*Font, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseFont(file)
+}
+
+func ParseFont(r io.Reader) (*Font, error) {
+	scanner := bufio.NewScanner(r)
+	lineno := 0
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		lineno++
+		return strings.TrimRight(scanner.Text(), "\r"), true
+	}
+
+	header, ok := next()
+	if !ok || !strings.HasPrefix(header, "flf2a") || len(header) < 6 {
+		return nil, fmt.Errorf("flf: missing flf2a header")
+	}
+	fields := strings.Fields(header[6:])
+	if len(fields) < 5 {
+		return nil, fmt.Errorf("flf: incomplete header: %q", header)
+	}
+	var nums [5]int
+	for i := range nums {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("flf: invalid header field %q", fields[i])
+		}
+		nums[i] = n
+	}
+	font := &Font{
+		height:    nums[0],
+		hardblank: []rune(header)[5],
+		glyphs:    make(map[rune][][]rune),
+	}
+	if font.height < 1 {
+		return nil, fmt.Errorf("flf: invalid height %d", font.height)
+	}
+	for i := 0; i < nums[4]; i++ {
+		if _, ok := next(); !ok {
+			return nil, fmt.Errorf("flf: unexpected end of comments")
+		}
+	}
+
+	readGlyph := func() ([][]rune, error) {
+		glyph := make([][]rune, font.height)
+		width := 0
+		for i := range glyph {
+			line, ok := next()
+			if !ok {
+				return nil, io.ErrUnexpectedEOF
+			}
+			glyph[i] = stripEndmarks([]rune(line))
+			if len(glyph[i]) > width {
+				width = len(glyph[i])
+			}
+		}
+		// keep the rows aligned even for sloppy fonts
+		for i, row := range glyph {
+			for len(row) < width {
+				row = append(row, font.hardblank)
+			}
+			glyph[i] = row
+		}
+		return glyph, nil
+	}
+
+	for ch := rune(32); ch < 127; ch++ {
+		glyph, err := readGlyph()
+		if err != nil {
+			return nil, fmt.Errorf("flf: line %d: character %q: %v", lineno, ch, err)
+		}
+		font.glyphs[ch] = glyph
+	}
+	for _, ch := range deutschRunes {
+		glyph, err := readGlyph()
+		if err == io.ErrUnexpectedEOF {
+			return font, nil
+		} else if err != nil {
+			return nil, err
+		}
+		font.glyphs[ch] = glyph
+	}
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+		tag := strings.Fields(line)
+		if len(tag) == 0 {
+			continue
+		}
+		code, err := strconv.ParseInt(tag[0], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("flf: line %d: invalid code tag %q", lineno, tag[0])
+		}
+		glyph, err := readGlyph()
+		if err != nil {
+			return nil, fmt.Errorf("flf: line %d: character %d: %v", lineno, code, err)
+		}
+		font.glyphs[rune(code)] = glyph
+	}
+	return font, nil
+}
+
+func mustParseFont(src string) *Font {
+	font, err := ParseFont(strings.NewReader(src))
+	if err != nil {
+		panic(err)
+	}
+	return font
+}
+
+// The last character of a glyph line is its endmark,
+// the last line of a glyph doubles it.
+func stripEndmarks(line []rune) []rune {
+	for len(line) > 0 && (line[len(line)-1] == ' ' || line[len(line)-1] == '\t') {
+		line = line[:len(line)-1]
+	}
+	if len(line) == 0 {
+		return line
+	}
+	mark := line[len(line)-1]
+	for len(line) > 0 && line[len(line)-1] == mark {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+func (font *Font) Height() int { return font.height }
+
+// Missing characters are drawn with glyph 0
+// if the font has one, and skipped otherwise.
+func (font *Font) glyph(ch rune) [][]rune {
+	if glyph, ok := font.glyphs[ch]; ok {
+		return glyph
+	}
+	return font.glyphs[0]
+}
+
+// Render returns the rows of s set in the font,
+// with hardblanks replaced by spaces.
+func (font *Font) Render(s string) []string {
+	var rows []string
+	for _, line := range strings.Split(s, "\n") {
+		buffer := make([][]rune, font.height)
+		for _, ch := range line {
+			glyph := font.glyph(ch)
+			if glyph == nil {
+				continue
+			}
+			for i := range buffer {
+				buffer[i] = append(buffer[i], glyph[i]...)
+			}
+		}
+		for _, row := range buffer {
+			for i, ch := range row {
+				if ch == font.hardblank {
+					row[i] = ' '
+				}
+			}
+			rows = append(rows, string(row))
+		}
+	}
+	return rows
+}
+
+func Banner(s string, font *Font) Layer {
+	if font == nil {
+		font = DefaultFont
+	}
+	rows := font.Render(s)
+	w := 0
+	for _, row := range rows {
+		length := len([]rune(row))
+		if length > w {
+			w = length
+		}
+	}
+	return Size(w, len(rows), RenderLayer(func(canvas Canvas) {
+		for y, row := range rows {
+			for x, ch := range []rune(row) {
+				canvas.Draw(x, y, ch, 0, 0)
+			}
+		}
+	}))
+}
@@ -0,0 +1,60 @@
+package wind
+
+import (
+	"github.com/nvlled/wind/size"
+	"strings"
+	"testing"
+)
+
+const testFont = `flf2a$ 2 2 4 -1 1
+two row test font
+$@
+$@@
+` + "##@\n##@@\n"
+
+func TestParseFont(t *testing.T) {
+	src := testFont
+	for ch := 34; ch < 127; ch++ {
+		src += "x@\nx@@\n"
+	}
+	for range deutschRunes {
+		src += "x@\nx@@\n"
+	}
+	src += "0x263A smiley\n:)@\n:)@@\n"
+	font, err := ParseFont(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := font.Render("! ☺")
+	if rows[0] != "## :)" || rows[1] != "## :)" {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+	if _, err := ParseFont(strings.NewReader("flf2a$ 2 2")); err == nil {
+		t.Error("expected an error for an incomplete header")
+	}
+}
+
+func TestBanner(t *testing.T) {
+	layer := Banner("Hi\n42", nil)
+	h := DefaultFont.Height() * 2
+	if !layer.Height().Equals(size.Const(h)) {
+		t.Errorf("banner height = %v, want %d", layer.Height(), h)
+	}
+	canvas := NewStringCanvas(40, h+2)
+	Border('-', '|', AlignCenter(layer)).Render(canvas)
+	want := "|--------------------------------------|\n" +
+		"|             █   █ ███                |\n" +
+		"|             █   █  █                 |\n" +
+		"|             █████  █                 |\n" +
+		"|             █   █  █                 |\n" +
+		"|             █   █ ███                |\n" +
+		"|             █   █  ███               |\n" +
+		"|             █   █ █   █              |\n" +
+		"|             █████   ██               |\n" +
+		"|                 █  █                 |\n" +
+		"|                 █ █████              |\n" +
+		"|--------------------------------------|\n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
@@ -0,0 +1,62 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"unicode/utf8"
+)
+
+// ButtonLayer is a focusable label that runs
+// an action on Enter, Space or a click.
+type ButtonLayer struct {
+	label   string
+	onPress func()
+	focused bool
+}
+
+func Button(label string, onPress func()) *ButtonLayer {
+	return &ButtonLayer{label: label, onPress: onPress}
+}
+
+func (b *ButtonLayer) Label() string { return b.label }
+
+func (b *ButtonLayer) SetLabel(label string) *ButtonLayer {
+	b.label = label
+	return b
+}
+
+// Press runs the action as if the button was pressed.
+func (b *ButtonLayer) Press() {
+	if b.onPress != nil {
+		b.onPress()
+	}
+}
+
+func (b *ButtonLayer) Width() size.T  { return size.Const(utf8.RuneCountInString(b.label) + 4) }
+func (b *ButtonLayer) Height() size.T { return size.Const(1) }
+
+func (b *ButtonLayer) SetFocused(focused bool) { b.focused = focused }
+
+func (b *ButtonLayer) Render(canvas Canvas) {
+	var fg uint16
+	if b.focused {
+		fg = uint16(term.AttrReverse)
+	}
+	canvas.DrawText(0, 0, "[ "+b.label+" ]", fg, 0)
+}
+
+func (b *ButtonLayer) HandleKey(e term.Event) bool {
+	if e.Key == term.KeyEnter || e.Key == term.KeySpace || e.Ch == ' ' {
+		b.Press()
+		return true
+	}
+	return false
+}
+
+func (b *ButtonLayer) HandleMouse(e term.Event, x, y int) bool {
+	if e.Key == term.MouseLeft && e.Mod&term.ModMotion == 0 {
+		b.Press()
+		return true
+	}
+	return false
+}
@@ -154,6 +154,10 @@ func (ccanvas *ColorCanvas) New(x, y, width, height int) Canvas {
 	}
 }
 
+func (ccanvas *ColorCanvas) unwrap() Canvas {
+	return ccanvas.canvas
+}
+
 func (ccanvas *ColorCanvas) Width() int {
 	return ccanvas.canvas.Width()
 }
@@ -0,0 +1,304 @@
+package wind
+
+import (
+	"github.com/nvlled/wind/size"
+	"strconv"
+	"unicode/utf8"
+)
+
+// chartRange is the range of values that fills a chart,
+// either fixed or from 0, or the lowest value if it is
+// negative, to the highest value.
+type chartRange struct {
+	lo, hi float64
+	fixed  bool
+}
+
+func (r chartRange) of(values []float64) (lo, hi float64) {
+	if r.fixed {
+		return r.lo, r.hi
+	}
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	return lo, hi
+}
+
+// scaleValue returns how many eighths of cells v fills
+func scaleValue(v, lo, hi float64, cells int) int {
+	if hi <= lo {
+		return 0
+	}
+	return clamp(int((v-lo)/(hi-lo)*float64(cells*8)+0.5), 0, cells*8)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// drawColumn draws a bar of eighths going up from the bottom
+// of a column h cells high, with its top at y
+func drawColumn(canvas Canvas, x, y, h, eighths int, fg, bg uint16) {
+	for row := 0; row < h; row++ {
+		fill := clamp(eighths-row*8, 0, 8)
+		canvas.Draw(x, y+h-1-row, lowerEighths[fill], fg, bg)
+	}
+}
+
+// drawValueAxis draws hi at the top and lo at the bottom of a
+// gutter h cells high, and returns the width of the gutter
+// with the space after it
+func drawValueAxis(canvas Canvas, h int, lo, hi float64, fg, bg uint16) int {
+	top, bottom := formatValue(hi), formatValue(lo)
+	w := max(utf8.RuneCountInString(top), utf8.RuneCountInString(bottom))
+	canvas.DrawText(w-utf8.RuneCountInString(top), 0, top, fg, bg)
+	if h > 1 {
+		canvas.DrawText(w-utf8.RuneCountInString(bottom), h-1, bottom, fg, bg)
+	}
+	return w + 1
+}
+
+// SparklineLayer draws values as columns, one per value, using the
+// ▁▂▃▄▅▆▇█ ramp and as many lines as it gets. When there are more
+// values than columns, the last ones are shown.
+type SparklineLayer struct {
+	values []float64
+	rng    chartRange
+	axis   bool
+
+	fg, bg         uint16
+	axisFg, axisBg uint16
+}
+
+func Sparkline(values []float64) *SparklineLayer {
+	return &SparklineLayer{values: values}
+}
+
+func (s *SparklineLayer) Values() []float64 { return s.values }
+
+func (s *SparklineLayer) SetValues(values []float64) *SparklineLayer {
+	s.values = values
+	return s
+}
+
+// Push adds a value at the end, dropping values from the
+// start to keep at most limit of them, if limit is positive.
+func (s *SparklineLayer) Push(value float64, limit int) *SparklineLayer {
+	s.values = append(s.values, value)
+	if limit > 0 && len(s.values) > limit {
+		s.values = s.values[len(s.values)-limit:]
+	}
+	return s
+}
+
+// SetRange fixes the values at the bottom and the top of
+// the chart. Without it, the range is from 0, or the lowest
+// value if it is negative, to the highest value.
+func (s *SparklineLayer) SetRange(lo, hi float64) *SparklineLayer {
+	s.rng = chartRange{lo, hi, true}
+	return s
+}
+
+func (s *SparklineLayer) AutoRange() *SparklineLayer {
+	s.rng = chartRange{}
+	return s
+}
+
+// ShowAxis shows the top and bottom of the range on the left.
+func (s *SparklineLayer) ShowAxis(show bool) *SparklineLayer {
+	s.axis = show
+	return s
+}
+
+func (s *SparklineLayer) SetStyle(fg, bg uint16) *SparklineLayer {
+	s.fg, s.bg = fg, bg
+	return s
+}
+
+func (s *SparklineLayer) SetAxisStyle(fg, bg uint16) *SparklineLayer {
+	s.axisFg, s.axisBg = fg, bg
+	return s
+}
+
+func (s *SparklineLayer) Width() size.T  { return size.Free }
+func (s *SparklineLayer) Height() size.T { return size.Free }
+
+func (s *SparklineLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	lo, hi := s.rng.of(s.values)
+	x := 0
+	if s.axis {
+		x = drawValueAxis(canvas, h, lo, hi, s.axisFg, s.axisBg)
+	}
+	values := s.values
+	if n := w - x; len(values) > n {
+		values = values[len(values)-max(n, 0):]
+	}
+	for _, v := range values {
+		drawColumn(canvas, x, 0, h, scaleValue(v, lo, hi, h), s.fg, s.bg)
+		x++
+	}
+}
+
+type Bar struct {
+	Label string
+	Value float64
+
+	// the colour of the bar, 0 for the colour of the chart
+	Fg uint16
+}
+
+// BarChartLayer draws a bar for each value with its label under it,
+// or with the label on the left when the bars are horizontal. The
+// bars share the width or height they get, unless a bar width is set.
+type BarChartLayer struct {
+	bars       []Bar
+	horizontal bool
+	barWidth   int
+	rng        chartRange
+	axis       bool
+
+	fg, bg           uint16
+	labelFg, labelBg uint16
+}
+
+func BarChart(bars ...Bar) *BarChartLayer {
+	return &BarChartLayer{bars: bars}
+}
+
+func (c *BarChartLayer) Bars() []Bar { return c.bars }
+
+func (c *BarChartLayer) SetBars(bars ...Bar) *BarChartLayer {
+	c.bars = bars
+	return c
+}
+
+func (c *BarChartLayer) SetHorizontal(horizontal bool) *BarChartLayer {
+	c.horizontal = horizontal
+	return c
+}
+
+// SetBarWidth sets how many columns a vertical bar takes.
+// With 0, the bars fill the width of the chart.
+func (c *BarChartLayer) SetBarWidth(width int) *BarChartLayer {
+	c.barWidth = width
+	return c
+}
+
+// SetRange works as in Sparkline.
+func (c *BarChartLayer) SetRange(lo, hi float64) *BarChartLayer {
+	c.rng = chartRange{lo, hi, true}
+	return c
+}
+
+func (c *BarChartLayer) AutoRange() *BarChartLayer {
+	c.rng = chartRange{}
+	return c
+}
+
+// ShowAxis shows the ends of the range, on the left of
+// vertical bars or under horizontal ones.
+func (c *BarChartLayer) ShowAxis(show bool) *BarChartLayer {
+	c.axis = show
+	return c
+}
+
+func (c *BarChartLayer) SetStyle(fg, bg uint16) *BarChartLayer {
+	c.fg, c.bg = fg, bg
+	return c
+}
+
+// SetLabelStyle sets the colours of the labels and the axis.
+func (c *BarChartLayer) SetLabelStyle(fg, bg uint16) *BarChartLayer {
+	c.labelFg, c.labelBg = fg, bg
+	return c
+}
+
+func (c *BarChartLayer) Width() size.T  { return size.Free }
+func (c *BarChartLayer) Height() size.T { return size.Free }
+
+func (c *BarChartLayer) values() []float64 {
+	var values []float64
+	for _, bar := range c.bars {
+		values = append(values, bar.Value)
+	}
+	return values
+}
+
+func (c *BarChartLayer) barFg(bar Bar) uint16 {
+	if bar.Fg != 0 {
+		return bar.Fg
+	}
+	return c.fg
+}
+
+func (c *BarChartLayer) Render(canvas Canvas) {
+	if c.horizontal {
+		c.renderHorizontal(canvas)
+	} else {
+		c.renderVertical(canvas)
+	}
+}
+
+func (c *BarChartLayer) renderVertical(canvas Canvas) {
+	w, h := canvas.Dimension()
+	n := len(c.bars)
+	if n == 0 || h < 2 {
+		return
+	}
+	lo, hi := c.rng.of(c.values())
+	h-- // for the labels
+	x := 0
+	if c.axis {
+		x = drawValueAxis(canvas, h, lo, hi, c.labelFg, c.labelBg)
+	}
+	bw := c.barWidth
+	if bw <= 0 {
+		bw = max(1, (w-x-(n-1))/n)
+	}
+	for _, bar := range c.bars {
+		if x >= w {
+			break
+		}
+		eighths := scaleValue(bar.Value, lo, hi, h)
+		for i := 0; i < bw; i++ {
+			drawColumn(canvas, x+i, 0, h, eighths, c.barFg(bar), c.bg)
+		}
+		label := []rune(bar.Label)
+		if len(label) > bw {
+			label = label[:bw]
+		}
+		canvas.DrawText(x+(bw-len(label))/2, h, string(label), c.labelFg, c.labelBg)
+		x += bw + 1
+	}
+}
+
+func (c *BarChartLayer) renderHorizontal(canvas Canvas) {
+	w, h := canvas.Dimension()
+	lo, hi := c.rng.of(c.values())
+	x := 0
+	for _, bar := range c.bars {
+		x = max(x, utf8.RuneCountInString(bar.Label)+1)
+	}
+	length := w - x
+	if c.axis && h > 0 {
+		h--
+		bottom, top := formatValue(lo), formatValue(hi)
+		canvas.DrawText(x, h, bottom, c.labelFg, c.labelBg)
+		// the top value is left out when the two would touch
+		if topX := w - utf8.RuneCountInString(top); topX > x+utf8.RuneCountInString(bottom) {
+			canvas.DrawText(topX, h, top, c.labelFg, c.labelBg)
+		}
+	}
+	for y, bar := range c.bars {
+		if y >= h {
+			break
+		}
+		canvas.DrawText(0, y, bar.Label, c.labelFg, c.labelBg)
+		eighths := scaleValue(bar.Value, lo, hi, length)
+		for i := 0; i < length; i++ {
+			fill := clamp(eighths-i*8, 0, 8)
+			canvas.Draw(x+i, y, leftEighths[fill], c.barFg(bar), c.bg)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package wind
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	spark := Sparkline([]float64{0, 1, 2, 3, 4, 5, 6, 7, 8})
+	canvas := NewStringCanvas(8, 1)
+	spark.Render(canvas)
+	if got := canvas.String(); got != "▁▂▃▄▅▆▇█\n" {
+		t.Errorf("last values: %q", got)
+	}
+	spark.SetValues([]float64{2, 8}).SetRange(0, 16)
+	canvas = NewStringCanvas(2, 2)
+	spark.Render(canvas)
+	if got := canvas.String(); got != "  \n▂█\n" {
+		t.Errorf("fixed range: %q", got)
+	}
+	spark.AutoRange().ShowAxis(true).Push(4, 2)
+	canvas = NewStringCanvas(4, 2)
+	spark.Render(canvas)
+	if got := canvas.String(); got != "8 █ \n0 ██\n" {
+		t.Errorf("axis: %q", got)
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	chart := BarChart(Bar{Label: "a", Value: 4}, Bar{Label: "bb", Value: 2})
+	canvas := NewStringCanvas(5, 3)
+	chart.Render(canvas)
+	if got := canvas.String(); got != "██   \n██ ██\na  bb\n" {
+		t.Errorf("vertical: %q", got)
+	}
+	chart.SetHorizontal(true).ShowAxis(true)
+	canvas = NewStringCanvas(7, 3)
+	chart.Render(canvas)
+	if got := canvas.String(); got != "a  ████\nbb ██  \n   0  4\n" {
+		t.Errorf("horizontal: %q", got)
+	}
+
+	chart = BarChart(Bar{Label: "a", Value: 40}, Bar{Label: "bb", Value: 20})
+	chart.SetHorizontal(true).ShowAxis(true)
+	canvas = NewStringCanvas(1, 3)
+	chart.Render(canvas)
+	if got := canvas.String(); got != "a\nb\n \n" {
+		t.Errorf("narrow horizontal: %q", got)
+	}
+}
@@ -0,0 +1,449 @@
+package wind
+
+import (
+	"fmt"
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"strings"
+	"unicode"
+)
+
+type TokenKind int
+
+const (
+	TokenText TokenKind = iota
+	TokenKeyword
+	TokenType
+	TokenString
+	TokenNumber
+	TokenComment
+	TokenPunct
+	TokenKey
+	TokenAdded
+	TokenRemoved
+	TokenHunk
+	TokenHeader
+)
+
+type Token struct {
+	Text string
+	Kind TokenKind
+}
+
+// CodeTheme maps token kinds to foreground colors.
+// Kinds that are not in Tokens use the canvas default.
+type CodeTheme struct {
+	Tokens    map[TokenKind]uint16
+	Gutter    uint16
+	Highlight uint16
+}
+
+var DefaultCodeTheme = CodeTheme{
+	Tokens: map[TokenKind]uint16{
+		TokenKeyword: uint16(term.ColorBlue) | uint16(term.AttrBold),
+		TokenType:    uint16(term.ColorCyan),
+		TokenString:  uint16(term.ColorGreen),
+		TokenNumber:  uint16(term.ColorMagenta),
+		TokenComment: uint16(term.ColorYellow),
+		TokenKey:     uint16(term.ColorCyan),
+		TokenAdded:   uint16(term.ColorGreen),
+		TokenRemoved: uint16(term.ColorRed),
+		TokenHunk:    uint16(term.ColorMagenta),
+		TokenHeader:  uint16(term.AttrBold),
+	},
+	Gutter:    uint16(term.ColorYellow),
+	Highlight: uint16(term.ColorBlue),
+}
+
+type CodeLayer interface {
+	Layer
+	ShowLineNumbers(show bool) CodeLayer
+	// lines are numbered from 1, to is inclusive
+	HighlightLines(from, to int) CodeLayer
+	SetTheme(theme CodeTheme) CodeLayer
+}
+
+// CodeBlock highlights src as lang, which is one of
+// go, json, yaml (yml) or diff (patch). Other languages
+// are shown as plain text.
+func CodeBlock(src, lang string) CodeLayer {
+	src = strings.Replace(src, "\t", "    ", -1)
+	src = strings.TrimSuffix(src, "\n")
+	lines := strings.Split(src, "\n")
+	return &codeLayer{
+		lines:  Tokenize(lines, lang),
+		theme:  DefaultCodeTheme,
+		width:  maxRuneLen(lines),
+		height: len(lines),
+	}
+}
+
+type codeLayer struct {
+	lines       [][]Token
+	theme       CodeTheme
+	lineNumbers bool
+	highlights  [][2]int
+	width       int
+	height      int
+}
+
+func (code *codeLayer) ShowLineNumbers(show bool) CodeLayer {
+	code.lineNumbers = show
+	return code
+}
+
+func (code *codeLayer) HighlightLines(from, to int) CodeLayer {
+	code.highlights = append(code.highlights, [2]int{from, to})
+	return code
+}
+
+func (code *codeLayer) SetTheme(theme CodeTheme) CodeLayer {
+	code.theme = theme
+	return code
+}
+
+func (code *codeLayer) gutterWidth() int {
+	if !code.lineNumbers {
+		return 0
+	}
+	return len(fmt.Sprint(len(code.lines))) + 1
+}
+
+func (code *codeLayer) highlighted(lineno int) bool {
+	for _, r := range code.highlights {
+		if lineno >= r[0] && lineno <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (code *codeLayer) Width() size.T {
+	return size.Const(code.gutterWidth() + code.width)
+}
+
+func (code *codeLayer) Height() size.T {
+	return size.Const(code.height)
+}
+
+func (code *codeLayer) Render(canvas Canvas) {
+	gutter := code.gutterWidth()
+	w := canvas.Width()
+	for y, tokens := range code.lines {
+		var bg uint16
+		if code.highlighted(y + 1) {
+			bg = code.theme.Highlight
+		}
+		if gutter > 0 {
+			num := fmt.Sprintf("%*d ", gutter-1, y+1)
+			canvas.DrawText(0, y, num, code.theme.Gutter, bg)
+		}
+		x := gutter
+		for _, token := range tokens {
+			fg := code.theme.Tokens[token.Kind]
+			for _, ch := range token.Text {
+				canvas.Draw(x, y, ch, fg, bg)
+				x++
+			}
+		}
+		for ; x < w; x++ {
+			canvas.Draw(x, y, ' ', 0, bg)
+		}
+	}
+}
+
+func maxRuneLen(lines []string) int {
+	w := 0
+	for _, line := range lines {
+		if n := len([]rune(line)); n > w {
+			w = n
+		}
+	}
+	return w
+}
+
+// Tokenize splits each line into tokens.
+// Concatenating the tokens of a line gives back the line.
+func Tokenize(lines []string, lang string) [][]Token {
+	var lexLine func(line string) []Token
+	switch strings.ToLower(lang) {
+	case "go", "golang":
+		lexLine = (&goLexer{}).line
+	case "json":
+		lexLine = lexJSON
+	case "yaml", "yml":
+		lexLine = lexYAML
+	case "diff", "patch":
+		lexLine = lexDiff
+	default:
+		lexLine = func(line string) []Token {
+			return []Token{{line, TokenText}}
+		}
+	}
+	result := make([][]Token, len(lines))
+	for i, line := range lines {
+		result[i] = lexLine(line)
+	}
+	return result
+}
+
+var goKeywords = wordSet(`break case chan const continue default defer else
+	fallthrough for func go goto if import interface map package range
+	return select struct switch type var`)
+
+var goTypes = wordSet(`bool byte complex64 complex128 error float32 float64
+	int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64
+	uintptr any comparable true false iota nil`)
+
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		set[word] = true
+	}
+	return set
+}
+
+// lexer state that spans lines:
+// block comments and raw strings
+type goLexer struct {
+	inComment bool
+	inRaw     bool
+}
+
+func (lex *goLexer) line(line string) []Token {
+	var tokens []Token
+	s := []rune(line)
+	i := 0
+	emit := func(j int, kind TokenKind) {
+		tokens = append(tokens, Token{string(s[i:j]), kind})
+		i = j
+	}
+	for i < len(s) {
+		switch {
+		case lex.inComment:
+			j := indexFrom(s, i, "*/")
+			if j < 0 {
+				emit(len(s), TokenComment)
+			} else {
+				lex.inComment = false
+				emit(j+2, TokenComment)
+			}
+		case lex.inRaw:
+			j := indexFrom(s, i, "`")
+			if j < 0 {
+				emit(len(s), TokenString)
+			} else {
+				lex.inRaw = false
+				emit(j+1, TokenString)
+			}
+		case hasPrefixAt(s, i, "//"):
+			emit(len(s), TokenComment)
+		case hasPrefixAt(s, i, "/*"):
+			lex.inComment = true
+			i += 2
+			tokens = append(tokens, Token{"/*", TokenComment})
+		case s[i] == '`':
+			lex.inRaw = true
+			i++
+			tokens = append(tokens, Token{"`", TokenString})
+		case s[i] == '"' || s[i] == '\'':
+			emit(scanQuoted(s, i), TokenString)
+		case unicode.IsDigit(s[i]):
+			emit(scanNumber(s, i), TokenNumber)
+		case isIdentStart(s[i]):
+			j := scanIdent(s, i)
+			word := string(s[i:j])
+			kind := TokenText
+			if goKeywords[word] {
+				kind = TokenKeyword
+			} else if goTypes[word] {
+				kind = TokenType
+			}
+			emit(j, kind)
+		case unicode.IsSpace(s[i]):
+			emit(scanSpace(s, i), TokenText)
+		default:
+			emit(i+1, TokenPunct)
+		}
+	}
+	return mergeTokens(tokens)
+}
+
+func lexJSON(line string) []Token {
+	var tokens []Token
+	s := []rune(line)
+	i := 0
+	emit := func(j int, kind TokenKind) {
+		tokens = append(tokens, Token{string(s[i:j]), kind})
+		i = j
+	}
+	for i < len(s) {
+		switch {
+		case s[i] == '"':
+			j := scanQuoted(s, i)
+			kind := TokenString
+			if k := scanSpace(s, j); k < len(s) && s[k] == ':' {
+				kind = TokenKey
+			}
+			emit(j, kind)
+		case s[i] == '-' || unicode.IsDigit(s[i]):
+			emit(scanNumber(s, i+1), TokenNumber)
+		case unicode.IsLetter(s[i]):
+			emit(scanIdent(s, i), TokenKeyword)
+		case unicode.IsSpace(s[i]):
+			emit(scanSpace(s, i), TokenText)
+		default:
+			emit(i+1, TokenPunct)
+		}
+	}
+	return mergeTokens(tokens)
+}
+
+var yamlWords = wordSet(`true false yes no on off null ~
+	True False Yes No On Off Null TRUE FALSE NULL`)
+
+func lexYAML(line string) []Token {
+	var tokens []Token
+	s := []rune(line)
+	i := 0
+	emit := func(j int, kind TokenKind) {
+		tokens = append(tokens, Token{string(s[i:j]), kind})
+		i = j
+	}
+	emit(scanSpace(s, 0), TokenText)
+	if hasPrefixAt(s, i, "---") || hasPrefixAt(s, i, "...") {
+		emit(len(s), TokenHeader)
+	}
+	for i < len(s) && s[i] == '-' && (i+1 == len(s) || s[i+1] == ' ') {
+		emit(i+1, TokenPunct)
+		emit(scanSpace(s, i), TokenText)
+	}
+	// a key runs up to the first ": " outside of quotes
+	if i < len(s) && s[i] != '#' && s[i] != '"' && s[i] != '\'' {
+		for j := i; j < len(s) && s[j] != '#'; j++ {
+			if s[j] == ':' && (j+1 == len(s) || s[j+1] == ' ') {
+				emit(j, TokenKey)
+				emit(j+1, TokenPunct)
+				break
+			}
+		}
+	}
+	for i < len(s) {
+		switch {
+		case s[i] == '#' && (i == 0 || unicode.IsSpace(s[i-1])):
+			emit(len(s), TokenComment)
+		case s[i] == '"' || s[i] == '\'':
+			emit(scanQuoted(s, i), TokenString)
+		case unicode.IsSpace(s[i]):
+			emit(scanSpace(s, i), TokenText)
+		default:
+			// plain scalars run to the end of the line or a comment
+			j := i
+			for j < len(s) && !(s[j] == '#' && unicode.IsSpace(s[j-1])) {
+				j++
+			}
+			value := strings.TrimRightFunc(string(s[i:j]), unicode.IsSpace)
+			j = i + len([]rune(value))
+			kind := TokenString
+			if yamlWords[value] {
+				kind = TokenKeyword
+			} else if isNumberAt(s, i, j) || (s[i] == '-' && isNumberAt(s, i+1, j)) {
+				kind = TokenNumber
+			} else if len(value) == 1 && strings.ContainsAny(value, "[]{},|>&*!") {
+				kind = TokenPunct
+			}
+			emit(j, kind)
+		}
+	}
+	return mergeTokens(tokens)
+}
+
+func lexDiff(line string) []Token {
+	kind := TokenText
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+		strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		kind = TokenHeader
+	case strings.HasPrefix(line, "@@"):
+		kind = TokenHunk
+	case strings.HasPrefix(line, "+"):
+		kind = TokenAdded
+	case strings.HasPrefix(line, "-"):
+		kind = TokenRemoved
+	}
+	return []Token{{line, kind}}
+}
+
+func mergeTokens(tokens []Token) []Token {
+	var merged []Token
+	for _, token := range tokens {
+		if token.Text == "" {
+			continue
+		}
+		n := len(merged)
+		if n > 0 && merged[n-1].Kind == token.Kind {
+			merged[n-1].Text += token.Text
+		} else {
+			merged = append(merged, token)
+		}
+	}
+	return merged
+}
+
+func hasPrefixAt(s []rune, i int, prefix string) bool {
+	return strings.HasPrefix(string(s[i:]), prefix)
+}
+
+func indexFrom(s []rune, i int, sub string) int {
+	j := strings.Index(string(s[i:]), sub)
+	if j < 0 {
+		return -1
+	}
+	return i + len([]rune(string(s[i:])[:j]))
+}
+
+func isIdentStart(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch)
+}
+
+func scanIdent(s []rune, i int) int {
+	for i < len(s) && (isIdentStart(s[i]) || unicode.IsDigit(s[i])) {
+		i++
+	}
+	return i
+}
+
+func scanSpace(s []rune, i int) int {
+	for i < len(s) && unicode.IsSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func scanNumber(s []rune, i int) int {
+	start := i
+	for i < len(s) && (unicode.IsDigit(s[i]) || unicode.IsLetter(s[i]) ||
+		s[i] == '.' || s[i] == '_' ||
+		(i > start && (s[i] == '+' || s[i] == '-') && (s[i-1] == 'e' || s[i-1] == 'E'))) {
+		i++
+	}
+	return i
+}
+
+func isNumberAt(s []rune, i, j int) bool {
+	return i < j && unicode.IsDigit(s[i]) && scanNumber(s, i) == j
+}
+
+// returns the index after the closing quote,
+// or the end of the line for unterminated strings
+func scanQuoted(s []rune, i int) int {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == quote {
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,69 @@
+package wind
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	src := []string{
+		"func main() { // entry",
+		"\ts := `raw",
+		"string` + \"x\" /* a",
+		"b */ x := 0x1F",
+	}
+	for i, tokens := range Tokenize(src, "go") {
+		text := ""
+		for _, token := range tokens {
+			text += token.Text
+		}
+		if text != src[i] {
+			t.Errorf("line %d: tokens give %q, want %q", i, text, src[i])
+		}
+	}
+	lines := Tokenize(src, "go")
+	if lines[0][0] != (Token{"func", TokenKeyword}) {
+		t.Errorf("expected keyword, got %v", lines[0][0])
+	}
+	if lines[2][0] != (Token{"string`", TokenString}) {
+		t.Errorf("raw string should continue, got %v", lines[2][0])
+	}
+	if lines[3][0] != (Token{"b */", TokenComment}) {
+		t.Errorf("block comment should continue, got %v", lines[3][0])
+	}
+
+	yaml := Tokenize([]string{"- name: wind # layout", "  port: -8080"}, "yaml")
+	kinds := func(tokens []Token) (result []TokenKind) {
+		for _, token := range tokens {
+			if strings.TrimSpace(token.Text) != "" {
+				result = append(result, token.Kind)
+			}
+		}
+		return
+	}
+	if got := kinds(yaml[0]); len(got) != 5 || got[1] != TokenKey || got[4] != TokenComment {
+		t.Errorf("unexpected yaml tokens: %v", yaml[0])
+	}
+	if got := kinds(yaml[1]); got[len(got)-1] != TokenNumber {
+		t.Errorf("unexpected yaml tokens: %v", yaml[1])
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	src := strings.Repeat("{\"key\": [1, true, null]}\n", 10)
+	layer := CodeBlock(src, "json").ShowLineNumbers(true).HighlightLines(2, 3)
+	w, h := computeDimension(layer, NewStringCanvas(100, 100))
+	if w != 3+24 || h != 10 {
+		t.Errorf("code block size = %dx%d", w, h)
+	}
+	canvas := NewStringCanvas(w, h)
+	layer.Render(canvas)
+	want := ""
+	for i := 1; i <= 10; i++ {
+		want += fmt.Sprintf("%2d {\"key\": [1, true, null]}\n", i)
+	}
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
@@ -0,0 +1,377 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"time"
+)
+
+type KeyHandler interface {
+	// returns false to pass the event on to the parent layers
+	HandleKey(e term.Event) bool
+}
+
+type Focusable interface {
+	Layer
+	KeyHandler
+	SetFocused(focused bool)
+}
+
+// OnKey gives layer a key handler. Key events reach the handler
+// when the focused layer is inside layer and did not handle them.
+func OnKey(layer Layer, handler func(e term.Event) bool) Layer {
+	return &keyLayer{layer, handler}
+}
+
+type keyLayer struct {
+	layer   Layer
+	handler func(e term.Event) bool
+}
+
+func (k *keyLayer) Width() size.T               { return k.layer.Width() }
+func (k *keyLayer) Height() size.T              { return k.layer.Height() }
+func (k *keyLayer) Render(canvas Canvas)        { RenderChild(k.layer, canvas) }
+func (k *keyLayer) Children() []Layer           { return []Layer{k.layer} }
+func (k *keyLayer) HandleKey(e term.Event) bool { return k.handler(e) }
+
+func (k *keyLayer) HeightFor(width int) (int, bool) { return heightFor(k.layer, width) }
+
+// children returns the layers directly under layer
+// that are currently shown, so hidden tabs are skipped.
+func children(layer Layer) []Layer {
+	var layers []Layer
+	switch l := layer.(type) {
+	case Switcher:
+		layers = l.Shown()
+	case Container:
+		layers = l.Children()
+	}
+	var result []Layer
+	for _, l := range layers {
+		if l != nil {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// Focus keeps track of the focused layer in a layer tree.
+//
+// Key events go to the focused layer first, then to each of its
+// parents that is a KeyHandler, innermost first. Tab and shift-tab
+// move the focus when nothing handled the event.
+//
+// termbox has no key for shift-tab, the terminal sends it as ESC [ Z,
+// which arrives with term.InputEsc as Esc, '[' and Z. So Focus holds
+// back an Esc, and a '[' right after it, until the next event. A key
+// that comes within EscDelay of the Esc is delivered with Alt, as
+// terminals send alt-x as ESC x. A lone Esc is delivered by Expire,
+// which App calls at the Deadline, or with the next event.
+type Focus struct {
+	root    Layer
+	focused Focusable
+	held    []term.Event
+	heldAt  time.Time
+}
+
+// EscDelay is how soon the key after an Esc has to come
+// for the two to be sent by the terminal as one key.
+const EscDelay = 50 * time.Millisecond
+
+func NewFocus(root Layer) *Focus {
+	f := &Focus{root: root}
+	f.Refresh()
+	return f
+}
+
+func (f *Focus) Root() Layer        { return f.root }
+func (f *Focus) Focused() Focusable { return f.focused }
+
+func (f *Focus) SetFocus(layer Focusable) {
+	if f.focused != nil && !sameLayer(f.focused, layer) {
+		f.focused.SetFocused(false)
+	}
+	f.focused = layer
+	if layer != nil {
+		layer.SetFocused(true)
+	}
+}
+
+// focusFallback is a Focusable that takes the focus only
+// when no layer in it does, as a Scroll of plain text
+type focusFallback interface {
+	Focusable
+	focusFallback()
+}
+
+// Focusables returns the focusable layers in traversal order.
+func (f *Focus) Focusables() []Focusable {
+	var result []Focusable
+	var walk func(layer Layer)
+	walk = func(layer Layer) {
+		n := len(result)
+		if focusable, ok := layer.(Focusable); ok {
+			result = append(result, focusable)
+		}
+		for _, child := range children(layer) {
+			walk(child)
+		}
+		if _, ok := layer.(focusFallback); ok && len(result) > n+1 {
+			result = append(result[:n], result[n+1:]...)
+		}
+	}
+	walk(f.root)
+	return result
+}
+
+// canFocus tells whether layer is one of the Focusables
+func (f *Focus) canFocus(layer Focusable) bool {
+	for _, focusable := range f.Focusables() {
+		if sameLayer(focusable, layer) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh moves the focus when the focused layer is no longer
+// shown: back to the layer that had it before a popup, when the
+// popup closed, or else to the first focusable layer.
+func (f *Focus) Refresh() {
+	focusables := f.Focusables()
+	if f.indexIn(focusables) >= 0 {
+		return
+	}
+	var restore Focusable
+	for _, r := range f.restorers() {
+		if layer := r.restoredFocus(); layer != nil {
+			restore = layer
+		}
+		r.saveFocus(f.focused)
+	}
+	for _, focusable := range focusables {
+		if restore != nil && sameLayer(focusable, restore) {
+			f.SetFocus(focusable)
+			return
+		}
+	}
+	if len(focusables) > 0 {
+		f.SetFocus(focusables[0])
+	} else {
+		f.SetFocus(nil)
+	}
+}
+
+// focusRestorer is a layer that hides the focused layer for a
+// while, like an Overlay with its popups, and then gives the
+// focus back to it.
+type focusRestorer interface {
+	// saveFocus keeps the layer focused before it was hidden
+	saveFocus(focused Focusable)
+	// restoredFocus returns the layer to focus again, once
+	restoredFocus() Focusable
+}
+
+func (f *Focus) restorers() []focusRestorer {
+	var result []focusRestorer
+	var walk func(layer Layer)
+	walk = func(layer Layer) {
+		if r, ok := layer.(focusRestorer); ok {
+			result = append(result, r)
+		}
+		for _, child := range children(layer) {
+			walk(child)
+		}
+	}
+	walk(f.root)
+	return result
+}
+
+func (f *Focus) Next() { f.move(1) }
+func (f *Focus) Prev() { f.move(-1) }
+
+func (f *Focus) move(step int) {
+	focusables := f.Focusables()
+	n := len(focusables)
+	if n == 0 {
+		f.SetFocus(nil)
+		return
+	}
+	i := f.indexIn(focusables)
+	if i < 0 {
+		if step > 0 {
+			i = n - 1
+		} else {
+			i = 0
+		}
+	}
+	f.SetFocus(focusables[((i+step)%n+n)%n])
+}
+
+func (f *Focus) indexIn(focusables []Focusable) int {
+	if f.focused == nil {
+		return -1
+	}
+	for i, focusable := range focusables {
+		if sameLayer(focusable, f.focused) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Path returns the layers from the root down to target,
+// or nil if target is not shown.
+func (f *Focus) Path(target Layer) []Layer {
+	return layerPath(f.root, target)
+}
+
+func layerPath(root, target Layer) []Layer {
+	if sameLayer(root, target) {
+		return []Layer{root}
+	}
+	for _, child := range children(root) {
+		if path := layerPath(child, target); path != nil {
+			return append([]Layer{root}, path...)
+		}
+	}
+	return nil
+}
+
+// focusRequester is a layer that moves the focus between the
+// layers under it, like Form. Focus asks the layers on the path
+// to the focused layer after each key they handle, and App asks
+// the layers under the mouse after each mouse event. Requests
+// made outside of those, as by calling Form.Submit, are dropped.
+type focusRequester interface {
+	// requestedFocus returns the layer to focus, once
+	requestedFocus() Focusable
+}
+
+func (f *Focus) takeRequest(path []Layer) {
+	for _, layer := range path {
+		if r, ok := layer.(focusRequester); ok {
+			if target := r.requestedFocus(); target != nil {
+				f.SetFocus(target)
+			}
+		}
+	}
+}
+
+func dropRequests(path []Layer) {
+	for _, layer := range path {
+		if r, ok := layer.(focusRequester); ok {
+			r.requestedFocus()
+		}
+	}
+}
+
+// HandleEvent delivers a key event and reports whether
+// some layer, or the focus traversal, used it. A held
+// Esc is reported as used.
+func (f *Focus) HandleEvent(e term.Event) bool {
+	return f.handleEvent(e, nil)
+}
+
+// Deadline is when a held Esc is delivered on its own.
+func (f *Focus) Deadline() (time.Time, bool) {
+	return f.heldAt.Add(EscDelay), len(f.held) > 0
+}
+
+// Expire delivers a held Esc that no key followed in time,
+// and reports whether some layer used it.
+func (f *Focus) Expire(now time.Time) bool {
+	return f.expire(now, nil)
+}
+
+func (f *Focus) expire(now time.Time, fallback func(e term.Event) bool) bool {
+	if len(f.held) == 0 || now.Before(f.heldAt.Add(EscDelay)) {
+		return false
+	}
+	held := f.held
+	f.held = nil
+	if len(held) == 2 {
+		// ESC [ is alt-[
+		return f.deliver(withAlt(held[1]), fallback)
+	}
+	return f.deliver(held[0], fallback)
+}
+
+func withAlt(e term.Event) term.Event {
+	e.Mod |= term.ModAlt
+	return e
+}
+
+// handleEvent is HandleEvent that gives the events no layer used
+// to fallback, as App does with its keymap, the held keys included
+func (f *Focus) handleEvent(e term.Event, fallback func(e term.Event) bool) bool {
+	if e.Type != term.EventKey {
+		return false
+	}
+	now := time.Now()
+	f.expire(now, fallback)
+	plain := e.Mod == 0
+	switch {
+	case len(f.held) == 0 && plain && e.Key == term.KeyEsc && e.Ch == 0:
+		f.held, f.heldAt = []term.Event{e}, now
+		return true
+	case len(f.held) == 1 && plain && e.Ch == '[':
+		f.held = append(f.held, e)
+		return true
+	case len(f.held) == 2 && plain && e.Ch == 'Z':
+		f.held = nil
+		f.Prev()
+		return true
+	case len(f.held) == 2:
+		// ESC [ then another key is alt-[ and that key
+		bracket := f.held[1]
+		f.held = nil
+		f.deliver(withAlt(bracket), fallback)
+	case len(f.held) == 1:
+		f.held = nil
+		return f.deliver(withAlt(e), fallback)
+	}
+	return f.deliver(e, fallback)
+}
+
+func (f *Focus) deliver(e term.Event, fallback func(e term.Event) bool) bool {
+	if f.dispatch(e) {
+		return true
+	}
+	return fallback != nil && fallback(e)
+}
+
+func (f *Focus) dispatch(e term.Event) bool {
+	f.Refresh()
+	var path []Layer
+	if f.focused != nil {
+		path = f.Path(f.focused)
+	}
+	if path == nil {
+		path = []Layer{f.root}
+	}
+	dropRequests(path)
+	for i := len(path) - 1; i >= 0; i-- {
+		if handler, ok := path[i].(KeyHandler); ok && handler.HandleKey(e) {
+			f.takeRequest(path)
+			return true
+		}
+	}
+	if e.Key == term.KeyTab && e.Ch == 0 && len(f.Focusables()) > 0 {
+		f.Next()
+		return true
+	}
+	for _, layer := range path {
+		if c, ok := layer.(keyCapturer); ok && c.capturesKeys() {
+			return true
+		}
+	}
+	return false
+}
+
+// keyCapturer is a layer that keeps the keys that the layers
+// in it leave, as an Overlay does while a popup is open, so
+// they do not reach the keymap of the App
+type keyCapturer interface {
+	capturesKeys() bool
+}
@@ -0,0 +1,116 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+	"time"
+)
+
+type testFocusable struct {
+	Layer
+	name    string
+	focused bool
+	keys    []rune
+}
+
+func newTestFocusable(name string) *testFocusable {
+	return &testFocusable{Layer: TextLine(name), name: name}
+}
+
+func (t *testFocusable) SetFocused(focused bool) { t.focused = focused }
+
+func (t *testFocusable) HandleKey(e term.Event) bool {
+	if e.Ch == 'x' || e.Ch == 0 {
+		return false
+	}
+	t.keys = append(t.keys, e.Ch)
+	return true
+}
+
+func TestFocusTraversal(t *testing.T) {
+	a, b, c, d := newTestFocusable("a"), newTestFocusable("b"),
+		newTestFocusable("c"), newTestFocusable("d")
+	tab := Tab()
+	var bubbled []rune
+	root := Vlayer(
+		a,
+		Border('-', '|', OnKey(Hlayer(b, SetColor(1, 0, c)), func(e term.Event) bool {
+			if e.Ch != 'x' {
+				return false
+			}
+			bubbled = append(bubbled, e.Ch)
+			return true
+		})),
+		tab.SetElements(d),
+	)
+	tab.Hide()
+
+	focus := NewFocus(root)
+	if focus.Focused() != a || !a.focused {
+		t.Fatalf("expected a to be focused first")
+	}
+	tabKey := term.Event{Type: term.EventKey, Key: term.KeyTab}
+	focus.HandleEvent(tabKey)
+	focus.HandleEvent(tabKey)
+	if focus.Focused() != c || b.focused || a.focused {
+		t.Errorf("expected c to be focused")
+	}
+	focus.HandleEvent(tabKey)
+	if focus.Focused() != a {
+		t.Errorf("hidden tab should be skipped, focus wrapped to a")
+	}
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyEsc})
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: '['})
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: 'Z'})
+	if focus.Focused() != c {
+		t.Errorf("shift-tab should focus c")
+	}
+
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: 'y'})
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: 'x'})
+	if string(c.keys) != "y" || string(bubbled) != "x" {
+		t.Errorf("keys: %q, bubbled: %q", string(c.keys), string(bubbled))
+	}
+
+	tab.ShowIndex(0)
+	focus.SetFocus(d)
+	tab.Hide()
+	focus.Refresh()
+	if focus.Focused() != a || d.focused {
+		t.Errorf("focus should leave the hidden tab")
+	}
+}
+
+func TestFocusHeldEsc(t *testing.T) {
+	a := newTestFocusable("a")
+	focus := NewFocus(Vlayer(a))
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyEsc})
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: '['})
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: 'y'})
+	if string(a.keys) != "[y" {
+		t.Errorf("alt-[ should be delivered before y: %q", string(a.keys))
+	}
+
+	var esc []term.Event
+	b := OnKey(Vlayer(a), func(e term.Event) bool {
+		esc = append(esc, e)
+		return true
+	})
+	focus = NewFocus(b)
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyEsc})
+	if len(esc) != 0 {
+		t.Fatalf("Esc should be held")
+	}
+	deadline, ok := focus.Deadline()
+	if !ok || focus.Expire(deadline.Add(-time.Millisecond)) || !focus.Expire(deadline) {
+		t.Fatalf("Esc should be delivered at the deadline")
+	}
+	if len(esc) != 1 || esc[0].Key != term.KeyEsc || esc[0].Mod != 0 {
+		t.Errorf("lone Esc: %v", esc)
+	}
+
+	empty := NewFocus(Vlayer(TextLine("no focusables")))
+	if empty.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyTab}) {
+		t.Errorf("tab should not be used without focusables")
+	}
+}
@@ -0,0 +1,481 @@
+package wind
+
+// blockFont is the FIGlet source of DefaultFont.
+const blockFont = `flf2a$ 5 5 8 -1 1
+wind block font: full-width 5 row blocks, lowercase repeats uppercase
+    @
+    @
+    @
+    @
+    @@
+█ @
+█ @
+█ @
+  @
+█ @@
+█ █ @
+█ █ @
+    @
+    @
+    @@
+ █ █  @
+█████ @
+ █ █  @
+█████ @
+ █ █  @@
+ ████ @
+█ █   @
+ ███  @
+  █ █ @
+████  @@
+██  █ @
+██ █  @
+  █   @
+ █ ██ @
+█  ██ @@
+ ██   @
+█  █  @
+ ██ █ @
+█  █  @
+ ██ █ @@
+█ @
+█ @
+  @
+  @
+  @@
+ █ @
+█  @
+█  @
+█  @
+ █ @@
+█  @
+ █ @
+ █ @
+ █ @
+█  @@
+      @
+█ █ █ @
+ ███  @
+█ █ █ @
+      @@
+      @
+  █   @
+█████ @
+  █   @
+      @@
+   @
+   @
+   @
+ █ @
+█  @@
+     @
+     @
+████ @
+     @
+     @@
+  @
+  @
+  @
+  @
+█ @@
+    █ @
+   █  @
+  █   @
+ █    @
+█     @@
+ ███  @
+█  ██ @
+█ █ █ @
+██  █ @
+ ███  @@
+ █  @
+██  @
+ █  @
+ █  @
+███ @@
+ ███  @
+█   █ @
+  ██  @
+ █    @
+█████ @@
+████  @
+    █ @
+ ███  @
+    █ @
+████  @@
+█   █ @
+█   █ @
+█████ @
+    █ @
+    █ @@
+█████ @
+█     @
+████  @
+    █ @
+████  @@
+ ███  @
+█     @
+████  @
+█   █ @
+ ███  @@
+█████ @
+    █ @
+   █  @
+  █   @
+  █   @@
+ ███  @
+█   █ @
+ ███  @
+█   █ @
+ ███  @@
+ ███  @
+█   █ @
+ ████ @
+    █ @
+ ███  @@
+  @
+█ @
+  @
+█ @
+  @@
+   @
+ █ @
+   @
+ █ @
+█  @@
+   █ @
+  █  @
+ █   @
+  █  @
+   █ @@
+     @
+████ @
+     @
+████ @
+     @@
+█    @
+ █   @
+  █  @
+ █   @
+█    @@
+████  @
+    █ @
+  ██  @
+      @
+  █   @@
+ ███  @
+█ ███ @
+█ █ █ @
+█ ███ @
+ ███  @@
+ ███  @
+█   █ @
+█████ @
+█   █ @
+█   █ @@
+████  @
+█   █ @
+████  @
+█   █ @
+████  @@
+ ████ @
+█     @
+█     @
+█     @
+ ████ @@
+████  @
+█   █ @
+█   █ @
+█   █ @
+████  @@
+█████ @
+█     @
+████  @
+█     @
+█████ @@
+█████ @
+█     @
+████  @
+█     @
+█     @@
+ ████ @
+█     @
+█  ██ @
+█   █ @
+ ████ @@
+█   █ @
+█   █ @
+█████ @
+█   █ @
+█   █ @@
+███ @
+ █  @
+ █  @
+ █  @
+███ @@
+  ███ @
+   █  @
+   █  @
+█  █  @
+ ██   @@
+█   █ @
+█  █  @
+███   @
+█  █  @
+█   █ @@
+█     @
+█     @
+█     @
+█     @
+█████ @@
+█   █ @
+██ ██ @
+█ █ █ @
+█   █ @
+█   █ @@
+█   █ @
+██  █ @
+█ █ █ @
+█  ██ @
+█   █ @@
+ ███  @
+█   █ @
+█   █ @
+█   █ @
+ ███  @@
+████  @
+█   █ @
+████  @
+█     @
+█     @@
+ ███  @
+█   █ @
+█ █ █ @
+█  █  @
+ ██ █ @@
+████  @
+█   █ @
+████  @
+█  █  @
+█   █ @@
+ ████ @
+█     @
+ ███  @
+    █ @
+████  @@
+█████ @
+  █   @
+  █   @
+  █   @
+  █   @@
+█   █ @
+█   █ @
+█   █ @
+█   █ @
+ ███  @@
+█   █ @
+█   █ @
+█   █ @
+ █ █  @
+  █   @@
+█   █ @
+█   █ @
+█ █ █ @
+██ ██ @
+█   █ @@
+█   █ @
+ █ █  @
+  █   @
+ █ █  @
+█   █ @@
+█   █ @
+ █ █  @
+  █   @
+  █   @
+  █   @@
+█████ @
+   █  @
+  █   @
+ █    @
+█████ @@
+██ @
+█  @
+█  @
+█  @
+██ @@
+█     @
+ █    @
+  █   @
+   █  @
+    █ @@
+██ @
+ █ @
+ █ @
+ █ @
+██ @@
+ █  @
+█ █ @
+    @
+    @
+    @@
+     @
+     @
+     @
+     @
+████ @@
+█  @
+ █ @
+   @
+   @
+   @@
+ ███  @
+█   █ @
+█████ @
+█   █ @
+█   █ @@
+████  @
+█   █ @
+████  @
+█   █ @
+████  @@
+ ████ @
+█     @
+█     @
+█     @
+ ████ @@
+████  @
+█   █ @
+█   █ @
+█   █ @
+████  @@
+█████ @
+█     @
+████  @
+█     @
+█████ @@
+█████ @
+█     @
+████  @
+█     @
+█     @@
+ ████ @
+█     @
+█  ██ @
+█   █ @
+ ████ @@
+█   █ @
+█   █ @
+█████ @
+█   █ @
+█   █ @@
+███ @
+ █  @
+ █  @
+ █  @
+███ @@
+  ███ @
+   █  @
+   █  @
+█  █  @
+ ██   @@
+█   █ @
+█  █  @
+███   @
+█  █  @
+█   █ @@
+█     @
+█     @
+█     @
+█     @
+█████ @@
+█   █ @
+██ ██ @
+█ █ █ @
+█   █ @
+█   █ @@
+█   █ @
+██  █ @
+█ █ █ @
+█  ██ @
+█   █ @@
+ ███  @
+█   █ @
+█   █ @
+█   █ @
+ ███  @@
+████  @
+█   █ @
+████  @
+█     @
+█     @@
+ ███  @
+█   █ @
+█ █ █ @
+█  █  @
+ ██ █ @@
+████  @
+█   █ @
+████  @
+█  █  @
+█   █ @@
+ ████ @
+█     @
+ ███  @
+    █ @
+████  @@
+█████ @
+  █   @
+  █   @
+  █   @
+  █   @@
+█   █ @
+█   █ @
+█   █ @
+█   █ @
+ ███  @@
+█   █ @
+█   █ @
+█   █ @
+ █ █  @
+  █   @@
+█   █ @
+█   █ @
+█ █ █ @
+██ ██ @
+█   █ @@
+█   █ @
+ █ █  @
+  █   @
+ █ █  @
+█   █ @@
+█   █ @
+ █ █  @
+  █   @
+  █   @
+  █   @@
+█████ @
+   █  @
+  █   @
+ █    @
+█████ @@
+ ██ @
+ █  @
+██  @
+ █  @
+ ██ @@
+█ @
+█ @
+█ @
+█ @
+█ @@
+██  @
+ █  @
+ ██ @
+ █  @
+██  @@
+      @
+ ██ █ @
+█  █  @
+      @
+      @@
+`
@@ -0,0 +1,224 @@
+package wind
+
+import (
+	"errors"
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"strings"
+	"unicode/utf8"
+)
+
+// Valuer is a field that has a value for a Form,
+// as InputLayer and TextAreaLayer have.
+type Valuer interface {
+	Value() string
+}
+
+// Required is a validator for fields that must not be empty.
+func Required(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("required")
+	}
+	return nil
+}
+
+type formField struct {
+	label      string
+	field      Focusable
+	validators []func(value string) error
+	err        error
+}
+
+func (ff *formField) value() string {
+	if v, ok := ff.field.(Valuer); ok {
+		return v.Value()
+	}
+	return ""
+}
+
+func (ff *formField) validate() error {
+	ff.err = nil
+	for _, validate := range ff.validators {
+		if err := validate(ff.value()); err != nil {
+			ff.err = err
+			break
+		}
+	}
+	return ff.err
+}
+
+// FormLayer lays out labelled fields in rows, with the labels in a
+// column as wide as the longest label, and the validation error of
+// a field under it.
+//
+// Down, or Enter, moves the focus to the next field and Up to the
+// previous one, when the field does not use those keys itself.
+// Enter on the last field submits the form.
+type FormLayer struct {
+	fields   []*formField
+	button   *ButtonLayer
+	onSubmit func(values map[string]string)
+
+	tree    Layer
+	request Focusable
+	ref     layoutRef
+}
+
+func Form() *FormLayer {
+	return &FormLayer{}
+}
+
+// Field adds a row with field, which should be a Valuer to have
+// a value. The validators are run in order on submit, the first
+// error is shown under the field.
+func (form *FormLayer) Field(label string, field Focusable, validators ...func(value string) error) *FormLayer {
+	form.fields = append(form.fields, &formField{label, field, validators, nil})
+	form.tree = nil
+	return form
+}
+
+// SubmitButton adds a button under the fields that submits the form.
+func (form *FormLayer) SubmitButton(label string) *FormLayer {
+	form.button = Button(label, func() { form.Submit() })
+	form.tree = nil
+	return form
+}
+
+// OnSubmit sets the handler for a submit with no validation errors.
+func (form *FormLayer) OnSubmit(handler func(values map[string]string)) *FormLayer {
+	form.onSubmit = handler
+	return form
+}
+
+// Values returns the values of the fields by label.
+func (form *FormLayer) Values() map[string]string {
+	values := make(map[string]string)
+	for _, ff := range form.fields {
+		values[ff.label] = ff.value()
+	}
+	return values
+}
+
+// Errors returns the validation errors of the last submit by label.
+func (form *FormLayer) Errors() map[string]error {
+	errs := make(map[string]error)
+	for _, ff := range form.fields {
+		if ff.err != nil {
+			errs[ff.label] = ff.err
+		}
+	}
+	return errs
+}
+
+// SetError shows err under the field with label, as for errors
+// found after the submit. A nil err removes the error.
+func (form *FormLayer) SetError(label string, err error) *FormLayer {
+	for _, ff := range form.fields {
+		if ff.label == label {
+			ff.err = err
+		}
+	}
+	form.clearCache()
+	return form
+}
+
+// Submit validates the fields and runs the OnSubmit handler if
+// they are all valid. Otherwise the first invalid field is focused.
+func (form *FormLayer) Submit() bool {
+	var invalid *formField
+	for _, ff := range form.fields {
+		if ff.validate() != nil && invalid == nil {
+			invalid = ff
+		}
+	}
+	form.clearCache()
+	if invalid != nil {
+		form.request = invalid.field
+		return false
+	}
+	if form.onSubmit != nil {
+		form.onSubmit(form.Values())
+	}
+	return true
+}
+
+func (form *FormLayer) requestedFocus() Focusable {
+	request := form.request
+	form.request = nil
+	return request
+}
+
+// clearCache lets the rows grow or shrink by the error lines,
+// and the layers around the form with them
+func (form *FormLayer) clearCache() {
+	if form.tree != nil {
+		ClearCache(form.tree)
+	}
+	form.ref.clear()
+}
+
+func (form *FormLayer) layout() Layer {
+	if form.tree != nil {
+		return form.tree
+	}
+	w := 0
+	for _, ff := range form.fields {
+		w = max(w, utf8.RuneCountInString(ff.label)+2)
+	}
+	var rows []Layer
+	for i, ff := range form.fields {
+		ff := ff
+		field := OnKey(ff.field, form.fieldKeys(i))
+		errLine := Defer(func() Layer {
+			if ff.err == nil {
+				return nil
+			}
+			return SetColor(uint16(term.ColorRed), 0, TextLine(ff.err.Error()))
+		})
+		rows = append(rows, Hlayer(
+			Size(w, 1, TextLine(ff.label+":")),
+			Vlayer(field, errLine),
+		))
+	}
+	if form.button != nil {
+		rows = append(rows, Hlayer(Size(w, 1, blank{}), form.button))
+	}
+	form.tree = Vlayer(rows...)
+	return form.tree
+}
+
+// fieldKeys moves the focus from the i-th field
+func (form *FormLayer) fieldKeys(i int) func(e term.Event) bool {
+	return func(e term.Event) bool {
+		if e.Ch != 0 || e.Mod != 0 {
+			return false
+		}
+		next := i + 1
+		switch e.Key {
+		case term.KeyEnter:
+			if next == len(form.fields) {
+				form.Submit()
+				return true
+			}
+		case term.KeyArrowDown:
+		case term.KeyArrowUp:
+			next = i - 1
+		default:
+			return false
+		}
+		if next < 0 || next >= len(form.fields) {
+			return false
+		}
+		form.request = form.fields[next].field
+		return true
+	}
+}
+
+func (form *FormLayer) Width() size.T     { return form.layout().Width() }
+func (form *FormLayer) Height() size.T    { return form.layout().Height() }
+func (form *FormLayer) Children() []Layer { return []Layer{form.layout()} }
+
+func (form *FormLayer) Render(canvas Canvas) {
+	form.ref.record(canvas)
+	RenderChild(form.layout(), canvas)
+}
@@ -0,0 +1,142 @@
+package wind
+
+import (
+	"errors"
+	term "github.com/nsf/termbox-go"
+	"strings"
+	"testing"
+)
+
+func TestFormLayout(t *testing.T) {
+	name := Input()
+	name.SetText("wind")
+	form := Form().
+		Field("Name", name).
+		Field("E-mail", Input(), Required).
+		SubmitButton("Save")
+
+	canvas := NewStringCanvas(20, 4)
+	form.Render(canvas)
+	want := "Name:   wind        \n" +
+		"E-mail:             \n" +
+		"        [ Save ]    \n" +
+		"                    \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+
+	if form.Submit() {
+		t.Fatalf("the empty required field should fail")
+	}
+	canvas = NewStringCanvas(20, 4)
+	form.Render(canvas)
+	want = "Name:   wind        \n" +
+		"E-mail:             \n" +
+		"        required    \n" +
+		"        [ Save ]    \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render with error:\n%s", got)
+	}
+}
+
+func TestFormInLayout(t *testing.T) {
+	form := Form().Field("Name", Input(), Required).SubmitButton("Save")
+	root := Vlayer(form, TextLine("footer"))
+
+	canvas := NewStringCanvas(16, 4)
+	root.Render(canvas)
+	form.Submit()
+	canvas = NewStringCanvas(16, 4)
+	root.Render(canvas)
+	want := "Name:           \n" +
+		"      required  \n" +
+		"      [ Save ]  \n" +
+		"footer          \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("the layers around the form should make room:\n%s", got)
+	}
+}
+
+func TestFormFocusAndSubmit(t *testing.T) {
+	name, mail := Input(), Input()
+	var submitted map[string]string
+	form := Form().
+		Field("Name", name, Required).
+		Field("Mail", mail, func(value string) error {
+			if value != "" && !strings.ContainsRune(value, '@') {
+				return errors.New("not an address")
+			}
+			return nil
+		}).
+		OnSubmit(func(values map[string]string) { submitted = values })
+	focus := NewFocus(form)
+	focus.Refresh()
+	if focus.Focused() != name {
+		t.Fatalf("the first field should have the focus")
+	}
+
+	enter := term.Event{Type: term.EventKey, Key: term.KeyEnter}
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyArrowDown})
+	if focus.Focused() != mail {
+		t.Fatalf("Down should move to the next field")
+	}
+	typeKeys(mail, "x")
+	focus.HandleEvent(enter)
+	if submitted != nil {
+		t.Fatalf("invalid form was submitted")
+	}
+	if focus.Focused() != name {
+		t.Errorf("the first invalid field should get the focus")
+	}
+	if errs := form.Errors(); len(errs) != 2 || errs["Mail"].Error() != "not an address" {
+		t.Errorf("errors %v", errs)
+	}
+
+	typeKeys(name, "wind")
+	focus.HandleEvent(enter)
+	if focus.Focused() != mail {
+		t.Fatalf("Enter should move to the next field")
+	}
+	typeKeys(mail, "@y")
+	focus.HandleEvent(enter)
+	if submitted["Name"] != "wind" || submitted["Mail"] != "x@y" {
+		t.Errorf("submitted %v", submitted)
+	}
+	if len(form.Errors()) != 0 {
+		t.Errorf("errors should be cleared")
+	}
+
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyArrowUp})
+	if focus.Focused() != name {
+		t.Errorf("Up should move to the previous field")
+	}
+}
+
+func TestFormStaleRequest(t *testing.T) {
+	name, mail := Input(), Input()
+	form := Form().Field("Name", name, Required).Field("Mail", mail).SubmitButton("Save")
+	focus := NewFocus(form)
+	focus.SetFocus(mail)
+
+	form.Submit()
+	typeKeys(mail, "x")
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: 'y'})
+	if focus.Focused() != mail {
+		t.Errorf("a submit outside of a key event should not move the focus later")
+	}
+}
+
+func TestFormSubmitClick(t *testing.T) {
+	name := Input()
+	form := Form().Field("Name", name, Required).SubmitButton("Save")
+	app := NewApp(form)
+	app.hits.Render(app.root, NewStringCanvas(20, 3))
+	app.handleMouse(term.Event{Type: term.EventMouse, Key: term.MouseLeft, MouseX: 8, MouseY: 1})
+	if len(form.Errors()) != 1 {
+		t.Fatalf("the click should submit")
+	}
+	if app.focus.Focused() != name {
+		t.Errorf("a click on submit should focus the invalid field")
+	}
+	app.Quit()
+}
@@ -0,0 +1,229 @@
+package wind
+
+import (
+	"github.com/nvlled/wind/size"
+)
+
+// BorderStyle is the runes of a frame and which of its sides are
+// drawn. Corners are drawn where two drawn sides meet, a side
+// without its neighbours runs the whole length.
+type BorderStyle struct {
+	Horizontal, Vertical     rune
+	TopLeft, TopRight        rune
+	BottomLeft, BottomRight  rune
+	Top, Right, Bottom, Left bool
+}
+
+var (
+	SingleBorder  = BorderStyle{'─', '│', '┌', '┐', '└', '┘', true, true, true, true}
+	DoubleBorder  = BorderStyle{'═', '║', '╔', '╗', '╚', '╝', true, true, true, true}
+	RoundedBorder = BorderStyle{'─', '│', '╭', '╮', '╰', '╯', true, true, true, true}
+	HeavyBorder   = BorderStyle{'━', '┃', '┏', '┓', '┗', '┛', true, true, true, true}
+	ASCIIBorder   = BorderStyle{'-', '|', '+', '+', '+', '+', true, true, true, true}
+	NoBorder      = BorderStyle{}
+)
+
+// Sides returns the style with only the given sides drawn.
+func (style BorderStyle) Sides(top, right, bottom, left bool) BorderStyle {
+	style.Top, style.Right, style.Bottom, style.Left = top, right, bottom, left
+	return style
+}
+
+type Justify int
+
+const (
+	JustifyLeft Justify = iota
+	JustifyCenter
+	JustifyRight
+)
+
+// justify returns where text of length n starts in width w
+func (j Justify) justify(n, w int) int {
+	switch j {
+	case JustifyCenter:
+		return (w - n) / 2
+	case JustifyRight:
+		return w - n
+	}
+	return 0
+}
+
+// FrameLayer draws a border around a layer, with a
+// title on the top side and a footer on the bottom side.
+type FrameLayer struct {
+	layer  Layer
+	style  BorderStyle
+	fg, bg uint16
+
+	title, footer               string
+	titleJustify, footerJustify Justify
+
+	// inside JoinBorders, the right and bottom
+	// sides are drawn past the frame
+	joined bool
+}
+
+func Frame(style BorderStyle, layer Layer) *FrameLayer {
+	return &FrameLayer{layer: wrapNil(layer), style: style}
+}
+
+func (frame *FrameLayer) Layer() Layer       { return frame.layer }
+func (frame *FrameLayer) Style() BorderStyle { return frame.style }
+
+func (frame *FrameLayer) SetStyle(style BorderStyle) *FrameLayer {
+	frame.style = style
+	return frame
+}
+
+// SetColor sets the colours of the border, the title and the footer.
+func (frame *FrameLayer) SetColor(fg, bg uint16) *FrameLayer {
+	frame.fg, frame.bg = fg, bg
+	return frame
+}
+
+// SetTitle puts title on the top side. It is cut to fit,
+// and not shown when the top side is not drawn.
+func (frame *FrameLayer) SetTitle(title string, justify Justify) *FrameLayer {
+	frame.title, frame.titleJustify = title, justify
+	return frame
+}
+
+// SetFooter puts footer on the bottom side, as SetTitle does.
+func (frame *FrameLayer) SetFooter(footer string, justify Justify) *FrameLayer {
+	frame.footer, frame.footerJustify = footer, justify
+	return frame
+}
+
+// insets returns the size of each side
+func (frame *FrameLayer) insets() (top, right, bottom, left int) {
+	b := func(drawn bool) int {
+		if drawn {
+			return 1
+		}
+		return 0
+	}
+	s := frame.style
+	return b(s.Top), b(s.Right), b(s.Bottom), b(s.Left)
+}
+
+func (frame *FrameLayer) Width() size.T {
+	_, right, _, left := frame.insets()
+	if frame.joined {
+		right = 0
+	}
+	return frame.layer.Width().Add(size.Const(left + right))
+}
+
+func (frame *FrameLayer) Height() size.T {
+	top, _, bottom, _ := frame.insets()
+	if frame.joined {
+		bottom = 0
+	}
+	return frame.layer.Height().Add(size.Const(top + bottom))
+}
+
+// HeightFor leaves out the sides from width and adds them to the height
+func (frame *FrameLayer) HeightFor(width int) (int, bool) {
+	top, right, bottom, left := frame.insets()
+	if frame.joined {
+		right, bottom = 0, 0
+	}
+	h, ok := heightFor(frame.layer, max(0, width-left-right))
+	return h + top + bottom, ok
+}
+
+func (frame *FrameLayer) Children() []Layer { return []Layer{frame.layer} }
+
+func (frame *FrameLayer) Render(canvas Canvas) {
+	join, joined := findCanvas[*joinCanvas](canvas)
+	frame.setJoined(joined, canvas)
+	if joined {
+		frame.renderJoined(canvas, join.grid)
+		return
+	}
+	w, h := canvas.Dimension()
+	top, right, bottom, left := frame.insets()
+	frame.drawBorder(canvas, w, h)
+
+	inner := canvas.New(left, top, max(0, w-left-right), max(0, h-top-bottom))
+	RenderChild(frame.layer, inner)
+}
+
+// setJoined sizes the frame for where it is drawn, in a JoinBorders
+// or not. A frame that moved in or out of one was laid out at the
+// other size, so the layers around it lay it out again.
+func (frame *FrameLayer) setJoined(joined bool, canvas Canvas) {
+	if frame.joined == joined {
+		return
+	}
+	frame.joined = joined
+	var ref layoutRef
+	ref.record(canvas)
+	ref.clear()
+	if c, ok := findCanvas[*schedulerCanvas](canvas); ok {
+		c.scheduler.RequestRedraw(0)
+	}
+}
+
+func (frame *FrameLayer) drawBorder(canvas Canvas, w, h int) {
+	s, fg, bg := frame.style, frame.fg, frame.bg
+	if w <= 0 || h <= 0 {
+		return
+	}
+	if s.Top {
+		for x := 0; x < w; x++ {
+			canvas.Draw(x, 0, s.Horizontal, fg, bg)
+		}
+	}
+	if s.Bottom {
+		for x := 0; x < w; x++ {
+			canvas.Draw(x, h-1, s.Horizontal, fg, bg)
+		}
+	}
+	if s.Left {
+		for y := 0; y < h; y++ {
+			canvas.Draw(0, y, s.Vertical, fg, bg)
+		}
+	}
+	if s.Right {
+		for y := 0; y < h; y++ {
+			canvas.Draw(w-1, y, s.Vertical, fg, bg)
+		}
+	}
+	corner := func(x, y int, ch rune, a, b bool) {
+		if a && b {
+			canvas.Draw(x, y, ch, fg, bg)
+		}
+	}
+	corner(0, 0, s.TopLeft, s.Top, s.Left)
+	corner(w-1, 0, s.TopRight, s.Top, s.Right)
+	corner(0, h-1, s.BottomLeft, s.Bottom, s.Left)
+	corner(w-1, h-1, s.BottomRight, s.Bottom, s.Right)
+
+	if s.Top {
+		frame.drawLabel(canvas, 0, w, frame.title, frame.titleJustify)
+	}
+	if s.Bottom && h > 1 {
+		frame.drawLabel(canvas, h-1, w, frame.footer, frame.footerJustify)
+	}
+}
+
+// drawLabel puts text on a side between the corners
+func (frame *FrameLayer) drawLabel(canvas Canvas, y, w int, text string, justify Justify) {
+	if text != "" {
+		x, label := frame.label(w, text, justify)
+		canvas.DrawText(x, y, string(label), frame.fg, frame.bg)
+	}
+}
+
+// label returns text with a space on each end, cut to fit on a
+// side length cells long, and where it starts on the side
+func (frame *FrameLayer) label(length int, text string, justify Justify) (int, []rune) {
+	_, right, _, left := frame.insets()
+	room := length - left - right
+	label := []rune(" " + text + " ")
+	if len(label) > room {
+		label = label[:max(0, room)]
+	}
+	return left + justify.justify(len(label), room), label
+}
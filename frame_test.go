@@ -0,0 +1,42 @@
+package wind
+
+import "testing"
+
+func TestFrameStyles(t *testing.T) {
+	canvas := NewStringCanvas(6, 3)
+	Frame(RoundedBorder, Text("ab")).Render(canvas)
+	if got := canvas.String(); got != "╭────╮\n│ab  │\n╰────╯\n" {
+		t.Errorf("rounded:\n%s", got)
+	}
+
+	canvas = NewStringCanvas(6, 3)
+	frame := Frame(SingleBorder.Sides(true, false, true, true), Text("ab"))
+	frame.Render(canvas)
+	if got := canvas.String(); got != "┌─────\n│ab   \n└─────\n" {
+		t.Errorf("no right side:\n%s", got)
+	}
+	if w, h := computeDimension(frame, canvas); w != 3 || h != 3 {
+		t.Errorf("size %dx%d", w, h)
+	}
+}
+
+func TestFrameTitles(t *testing.T) {
+	canvas := NewStringCanvas(12, 3)
+	Frame(ASCIIBorder, nil).
+		SetTitle("top", JustifyCenter).
+		SetFooter("a long footer", JustifyRight).
+		Render(canvas)
+	want := "+-- top ---+\n" +
+		"|          |\n" +
+		"+ a long fo+\n"
+	if got := canvas.String(); got != want {
+		t.Errorf("titles:\n%s", got)
+	}
+	canvas = NewStringCanvas(12, 1)
+	Frame(DoubleBorder.Sides(true, false, false, false), nil).
+		SetTitle("x", JustifyRight).
+		Render(canvas)
+	if got := canvas.String(); got != "═════════ x \n" {
+		t.Errorf("right title: %q", got)
+	}
+}
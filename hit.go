@@ -0,0 +1,366 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"sort"
+)
+
+type MouseHandler interface {
+	// x and y are relative to the top left of the layer,
+	// returns false to pass the event on to the parent layers
+	HandleMouse(e term.Event, x, y int) bool
+}
+
+// RenderChild renders a sub layer. Layers that contain other
+// layers should render them through it, so that the render pass
+// can record where each layer ends up on the screen.
+func RenderChild(layer Layer, canvas Canvas) {
+	hits := hitMapOf(canvas)
+	if hits == nil {
+		layer.Render(canvas)
+		return
+	}
+	hits.push(layer, canvas)
+	layer.Render(canvas)
+	hits.pop()
+}
+
+// canvases that decorate another canvas
+type canvasWrapper interface {
+	unwrap() Canvas
+}
+
+// findCanvas looks through canvas wrappers
+// for a canvas of type T.
+func findCanvas[T Canvas](canvas Canvas) (T, bool) {
+	for canvas != nil {
+		if c, ok := canvas.(T); ok {
+			return c, true
+		}
+		wrapper, ok := canvas.(canvasWrapper)
+		if !ok {
+			break
+		}
+		canvas = wrapper.unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+func hitMapOf(canvas Canvas) *HitMap {
+	if c, ok := findCanvas[*hitCanvas](canvas); ok {
+		return c.hits
+	}
+	return nil
+}
+
+type hitCanvas struct {
+	Canvas
+	hits *HitMap
+}
+
+func (canvas *hitCanvas) unwrap() Canvas { return canvas.Canvas }
+
+func (canvas *hitCanvas) New(x, y, width, height int) Canvas {
+	return &hitCanvas{canvas.Canvas.New(x, y, width, height), canvas.hits}
+}
+
+type hitEntry struct {
+	layer  Layer
+	rect   rect
+	parent int
+
+	// the part of rect inside the parent entries,
+	// smaller than rect for scrolled content
+	visible rect
+
+	// what the layer was drawn on, and its size
+	// then, for drawing it again in the same place
+	canvas        Canvas
+	width, height size.T
+}
+
+// HitMap records the screen rectangle of every layer
+// drawn by Render, for finding the layers under the mouse.
+type HitMap struct {
+	entries []hitEntry
+	stack   []int
+
+	// the handler that took the last mouse press,
+	// it gets the drag events until the release
+	captured   MouseHandler
+	capturedAt rect
+}
+
+func NewHitMap() *HitMap {
+	return &HitMap{}
+}
+
+// Render renders layer on canvas, replacing the recorded rectangles.
+// The layers that were drawn before and are not drawn anymore,
+// like a Watch in a closed popup, let go of what they hold.
+func (hits *HitMap) Render(layer Layer, canvas Canvas) {
+	drawn := hits.releasers()
+	hits.entries = hits.entries[:0]
+	hits.stack = hits.stack[:0]
+	RenderChild(layer, &hitCanvas{canvas, hits})
+	hits.releaseGone(drawn)
+}
+
+// releasers returns the drawn layers that hold on to something
+func (hits *HitMap) releasers() []releaser {
+	var result []releaser
+	for _, entry := range hits.entries {
+		if r, ok := entry.layer.(releaser); ok {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// releaseGone releases the layers of drawn that are not drawn now
+func (hits *HitMap) releaseGone(drawn []releaser) {
+	if len(drawn) == 0 {
+		return
+	}
+	now := make(map[releaser]bool)
+	for _, r := range hits.releasers() {
+		now[r] = true
+	}
+	for _, r := range drawn {
+		if !now[r] {
+			r.release()
+		}
+	}
+}
+
+func (hits *HitMap) push(layer Layer, canvas Canvas) {
+	x, y := canvas.Base()
+	w, h := canvas.Dimension()
+	r := rect{x, y, w, h}
+	parent, visible := -1, r
+	if n := len(hits.stack); n > 0 {
+		parent = hits.stack[n-1]
+		visible = r.intersect(hits.entries[parent].visible)
+	}
+	hits.entries = append(hits.entries, hitEntry{
+		layer, r, parent, visible,
+		canvas, layer.Width(), layer.Height(),
+	})
+	hits.stack = append(hits.stack, len(hits.entries)-1)
+}
+
+func (hits *HitMap) pop() {
+	hits.stack = hits.stack[:len(hits.stack)-1]
+}
+
+// current is the layer being rendered
+func (hits *HitMap) current() Layer {
+	if n := len(hits.stack); n > 0 {
+		return hits.entries[hits.stack[n-1]].layer
+	}
+	return nil
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.width &&
+		y >= r.y && y < r.y+r.height
+}
+
+func (r rect) intersect(other rect) rect {
+	x, y := max(r.x, other.x), max(r.y, other.y)
+	w := min(r.x+r.width, other.x+other.width) - x
+	h := min(r.y+r.height, other.y+other.height) - y
+	return rect{x, y, max(w, 0), max(h, 0)}
+}
+
+// hit returns the index of the topmost, deepest entry at x, y.
+// Children are recorded after their parents and later siblings
+// are drawn over earlier ones, so that is the last entry to match.
+func (hits *HitMap) hit(x, y int) int {
+	for i := len(hits.entries) - 1; i >= 0; i-- {
+		if hits.entries[i].visible.contains(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// HitTest returns the layers at screen position x, y,
+// from the root layer down to the deepest one.
+func (hits *HitMap) HitTest(x, y int) []Layer {
+	var path []Layer
+	for i := hits.hit(x, y); i >= 0; i = hits.entries[i].parent {
+		path = append([]Layer{hits.entries[i].layer}, path...)
+	}
+	return path
+}
+
+// Rect returns where layer was drawn in the last render.
+func (hits *HitMap) Rect(layer Layer) (x, y, w, h int, ok bool) {
+	for _, entry := range hits.entries {
+		if sameLayer(entry.layer, layer) {
+			r := entry.rect
+			return r.x, r.y, r.width, r.height, true
+		}
+	}
+	return 0, 0, 0, 0, false
+}
+
+func (hits *HitMap) index(layer Layer) int {
+	for i, entry := range hits.entries {
+		if sameLayer(entry.layer, layer) {
+			return i
+		}
+	}
+	return -1
+}
+
+// subtreeEnd returns the index of the last entry under entry i.
+// Entries are recorded depth first, so the entries under i come
+// right after it, and the first entry after them has its parent
+// before i.
+func (hits *HitMap) subtreeEnd(i int) int {
+	end := i
+	for k := i + 1; k < len(hits.entries) && hits.entries[k].parent >= i; k++ {
+		end = k
+	}
+	return end
+}
+
+func (hits *HitMap) isAncestor(a, i int) bool {
+	for k := hits.entries[i].parent; k >= 0; k = hits.entries[k].parent {
+		if k == a {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapped tells whether entry i shares cells with
+// entries other than its own ancestors and descendants
+func (hits *HitMap) overlapped(i int) bool {
+	end := hits.subtreeEnd(i)
+	r := hits.entries[i].visible
+	for k, entry := range hits.entries {
+		if k >= i && k <= end || hits.isAncestor(k, i) {
+			continue
+		}
+		if o := r.intersect(entry.visible); o.width > 0 && o.height > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Update renders layers again where they were drawn in the last
+// render, on the same canvases, and records their new sub layers,
+// without rendering the rest of the tree. It draws nothing and
+// returns false when it cannot do that, because a layer was not
+// drawn, changed size, or shares cells with layers other than its
+// own; then the whole tree should be rendered with Render.
+func (hits *HitMap) Update(layers []Layer) bool {
+	var dirty []int
+	for _, layer := range layers {
+		i := hits.index(layer)
+		if i < 0 {
+			return false
+		}
+		entry := hits.entries[i]
+		if !entry.width.Equals(layer.Width()) || !entry.height.Equals(layer.Height()) {
+			return false
+		}
+		if hits.overlapped(i) {
+			return false
+		}
+		dirty = append(dirty, i)
+	}
+	// last first, so that the entries before
+	// an updated one keep their indexes
+	sort.Sort(sort.Reverse(sort.IntSlice(dirty)))
+	for n, i := range dirty {
+		if n > 0 && i == dirty[n-1] {
+			continue
+		}
+		inside := false
+		for _, j := range dirty {
+			inside = inside || hits.isAncestor(j, i)
+		}
+		if !inside {
+			hits.renderAgain(i)
+		}
+	}
+	return true
+}
+
+// renderAgain renders the layer of entry i, replacing
+// the entries under it
+func (hits *HitMap) renderAgain(i int) {
+	entry := hits.entries[i]
+	end := hits.subtreeEnd(i)
+	drawn := hits.releasers()
+	rest := append([]hitEntry(nil), hits.entries[end+1:]...)
+	hits.entries = hits.entries[:i+1]
+	hits.stack = append(hits.stack[:0], i)
+
+	canvas := entry.canvas
+	w, h := canvas.Dimension()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			canvas.Draw(x, y, ' ', 0, 0)
+		}
+	}
+	entry.layer.Render(canvas)
+
+	// the entries after the old ones have their parents
+	// before i, or after the old ones
+	moved := len(hits.entries) - (end + 1)
+	for _, e := range rest {
+		if e.parent > end {
+			e.parent += moved
+		}
+		hits.entries = append(hits.entries, e)
+	}
+	hits.stack = hits.stack[:0]
+	hits.releaseGone(drawn)
+}
+
+func isMousePress(e term.Event) bool {
+	return e.Key == term.MouseLeft || e.Key == term.MouseMiddle || e.Key == term.MouseRight
+}
+
+// Dispatch sends a mouse event to the deepest MouseHandler under it,
+// then to its parents until one of them handles it. A handler that
+// takes a button press also gets the drag events that follow,
+// wherever they happen, up to and including the release.
+func (hits *HitMap) Dispatch(e term.Event) bool {
+	if e.Type != term.EventMouse {
+		return false
+	}
+	x, y := e.MouseX, e.MouseY
+	if hits.captured != nil {
+		dragging := e.Mod&term.ModMotion != 0 || isMousePress(e)
+		if dragging || e.Key == term.MouseRelease {
+			handler, r := hits.captured, hits.capturedAt
+			if e.Key == term.MouseRelease {
+				hits.captured = nil
+			}
+			return handler.HandleMouse(e, x-r.x, y-r.y)
+		}
+		hits.captured = nil
+	}
+	for i := hits.hit(x, y); i >= 0; i = hits.entries[i].parent {
+		entry := hits.entries[i]
+		handler, ok := entry.layer.(MouseHandler)
+		if !ok || !handler.HandleMouse(e, x-entry.rect.x, y-entry.rect.y) {
+			continue
+		}
+		if isMousePress(e) && e.Mod&term.ModMotion == 0 {
+			hits.captured = handler
+			hits.capturedAt = entry.rect
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+type testClickable struct {
+	Layer
+	clicks [][2]int
+}
+
+func (c *testClickable) HandleMouse(e term.Event, x, y int) bool {
+	c.clicks = append(c.clicks, [2]int{x, y})
+	return true
+}
+
+func TestHitTest(t *testing.T) {
+	left := &testClickable{Layer: SizeW(10, stars)}
+	right := &testClickable{Layer: SetColor(1, 0, spikes)}
+	root := Border('-', '|', Hlayer(left, right))
+
+	hits := NewHitMap()
+	hits.Render(root, NewStringCanvas(30, 10))
+
+	path := hits.HitTest(12, 3)
+	if len(path) != 4 || !sameLayer(path[2], right) {
+		t.Fatalf("unexpected path: %v", path)
+	}
+	if !sameLayer(path[0], root) {
+		t.Errorf("path should start at the root")
+	}
+	if x, y, w, h, _ := hits.Rect(right); x != 11 || y != 1 || w != 18 || h != 8 {
+		t.Errorf("right rect = %d %d %d %d", x, y, w, h)
+	}
+	if path := hits.HitTest(0, 0); len(path) != 1 {
+		t.Errorf("border should be the deepest layer at 0, 0: %v", path)
+	}
+
+	press := term.Event{Type: term.EventMouse, Key: term.MouseLeft, MouseX: 3, MouseY: 2}
+	hits.Dispatch(press)
+	drag := press
+	drag.Mod = term.ModMotion
+	drag.MouseX = 20
+	hits.Dispatch(drag)
+	hits.Dispatch(term.Event{Type: term.EventMouse, Key: term.MouseRelease, MouseX: 25, MouseY: 2})
+	hits.Dispatch(term.Event{Type: term.EventMouse, Key: term.MouseWheelUp, MouseX: 25, MouseY: 2})
+	if len(left.clicks) != 3 || left.clicks[1] != [2]int{19, 1} {
+		t.Errorf("drag should stay with the left layer: %v", left.clicks)
+	}
+	if len(right.clicks) != 1 || right.clicks[0] != [2]int{14, 1} {
+		t.Errorf("wheel should go to the right layer: %v", right.clicks)
+	}
+}
+
+func TestHitMapUpdate(t *testing.T) {
+	text := "ab"
+	changing := SizeW(3, RenderLayer(func(canvas Canvas) { canvas.DrawText(0, 0, text, 0, 0) }))
+	left := &testClickable{Layer: Hlayer(Text("["), changing, Text("]"))}
+	right := &testClickable{Layer: Text("xy")}
+	root := Vlayer(left, right)
+
+	hits := NewHitMap()
+	canvas := NewStringCanvas(6, 2)
+	hits.Render(root, canvas)
+	entries := len(hits.entries)
+
+	text = "c"
+	if !hits.Update([]Layer{changing}) {
+		t.Fatalf("a layer of the same size should be drawn alone")
+	}
+	if got := canvas.String(); got != "[c  ] \nxy    \n" {
+		t.Errorf("update:\n%s", got)
+	}
+	text = "de"
+	if !hits.Update([]Layer{changing, left}) {
+		t.Fatalf("a layer with its parent should be drawn with it")
+	}
+	if got := canvas.String(); got != "[de ] \nxy    \n" {
+		t.Errorf("update with parent:\n%s", got)
+	}
+	if len(hits.entries) != entries {
+		t.Errorf("%d entries, want %d", len(hits.entries), entries)
+	}
+	if path := hits.HitTest(0, 1); len(path) < 2 || !sameLayer(path[1], right) {
+		t.Errorf("the layers after should keep their parents: %v", path)
+	}
+
+	if hits.Update([]Layer{Text("not drawn")}) {
+		t.Errorf("a layer that was not drawn needs a full render")
+	}
+	grown := &testClickable{Layer: Text("ab")}
+	hits.Render(Vlayer(Defer(func() Layer { return grown }), Text("z")), canvas)
+	grown.Layer = Text("abc")
+	if hits.Update([]Layer{grown}) {
+		t.Errorf("a layer that changed size needs a full render")
+	}
+
+	under := Text("ab")
+	hits.Render(Zlayer(under, Text("c")), canvas)
+	if hits.Update([]Layer{under}) {
+		t.Errorf("a layer under another needs a full render")
+	}
+}
@@ -0,0 +1,277 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"unicode"
+)
+
+// InputLayer is a one line text field. Besides the arrow, Home,
+// End, Backspace and Delete keys it takes the emacs keys: Ctrl-a,
+// Ctrl-e, Ctrl-b, Ctrl-f, Ctrl-d, Ctrl-k, Ctrl-u, Ctrl-w, and
+// Alt-b, Alt-f, Alt-d and Alt-Backspace for moving by words.
+// Up and Down browse the history, if it is enabled.
+type InputLayer struct {
+	text        []rune
+	cursor      int
+	offset      int
+	placeholder string
+	mask        rune
+	focused     bool
+
+	history     []string
+	historyOn   bool
+	historyPos  int
+	historyEdit []rune
+
+	onSubmit func(text string)
+	onChange func(text string)
+}
+
+func Input() *InputLayer {
+	return &InputLayer{}
+}
+
+func (input *InputLayer) Text() string { return string(input.text) }
+func (input *InputLayer) Cursor() int  { return input.cursor }
+
+// Value is the same as Text.
+func (input *InputLayer) Value() string { return input.Text() }
+
+func (input *InputLayer) SetText(text string) *InputLayer {
+	input.text = []rune(text)
+	input.cursor = len(input.text)
+	return input
+}
+
+func (input *InputLayer) SetPlaceholder(placeholder string) *InputLayer {
+	input.placeholder = placeholder
+	return input
+}
+
+// SetMask shows every character as mask,
+// for passwords. A mask of 0 shows the text.
+func (input *InputLayer) SetMask(mask rune) *InputLayer {
+	input.mask = mask
+	return input
+}
+
+// EnableHistory keeps submitted lines for Up and Down.
+func (input *InputLayer) EnableHistory() *InputLayer {
+	input.historyOn = true
+	input.historyPos = len(input.history)
+	return input
+}
+
+func (input *InputLayer) SetHistory(history []string) *InputLayer {
+	input.history = append([]string{}, history...)
+	return input.EnableHistory()
+}
+
+func (input *InputLayer) History() []string {
+	return append([]string{}, input.history...)
+}
+
+// OnSubmit sets the handler for Enter. Without one,
+// Enter is left to the parent layers.
+func (input *InputLayer) OnSubmit(handler func(text string)) *InputLayer {
+	input.onSubmit = handler
+	return input
+}
+
+func (input *InputLayer) OnChange(handler func(text string)) *InputLayer {
+	input.onChange = handler
+	return input
+}
+
+func (input *InputLayer) Width() size.T  { return size.Free }
+func (input *InputLayer) Height() size.T { return size.Const(1) }
+
+func (input *InputLayer) SetFocused(focused bool) { input.focused = focused }
+
+func (input *InputLayer) Render(canvas Canvas) {
+	w := canvas.Width()
+	if w <= 0 {
+		return
+	}
+	input.scroll(w)
+
+	placeholder := []rune(input.placeholder)
+	for x := 0; x < w; x++ {
+		i := input.offset + x
+		ch, fg := ' ', uint16(0)
+		if i < len(input.text) {
+			ch = input.text[i]
+			if input.mask != 0 {
+				ch = input.mask
+			}
+		} else if len(input.text) == 0 && x < len(placeholder) {
+			ch, fg = placeholder[x], uint16(term.AttrDim)
+		}
+		if input.focused && i == input.cursor {
+			fg = uint16(term.AttrReverse)
+		}
+		canvas.Draw(x, 0, ch, fg, 0)
+	}
+}
+
+// scroll keeps the cursor in view, using the
+// whole width when the text is long enough
+func (input *InputLayer) scroll(w int) {
+	lo := input.cursor - w + 1
+	hi := input.cursor
+	offset := clamp(input.offset, lo, hi)
+	if end := len(input.text) + 1 - w; offset > end {
+		offset = end
+	}
+	input.offset = clamp(offset, max(lo, 0), hi)
+}
+
+func (input *InputLayer) changed() {
+	if input.onChange != nil {
+		input.onChange(string(input.text))
+	}
+}
+
+func (input *InputLayer) insert(ch rune) {
+	text := make([]rune, 0, len(input.text)+1)
+	text = append(text, input.text[:input.cursor]...)
+	text = append(text, ch)
+	input.text = append(text, input.text[input.cursor:]...)
+	input.cursor++
+	input.changed()
+}
+
+// delete removes the text between the cursor and i
+func (input *InputLayer) delete(i int) {
+	from, to := input.cursor, clamp(i, 0, len(input.text))
+	if from > to {
+		from, to = to, from
+	}
+	if from == to {
+		return
+	}
+	input.text = append(input.text[:from:from], input.text[to:]...)
+	input.cursor = from
+	input.changed()
+}
+
+func isWordRune(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+// wordLeft and wordRight find word boundaries in text from i
+func wordLeft(text []rune, i int) int {
+	for i > 0 && !isWordRune(text[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(text[i-1]) {
+		i--
+	}
+	return i
+}
+
+func wordRight(text []rune, i int) int {
+	for i < len(text) && !isWordRune(text[i]) {
+		i++
+	}
+	for i < len(text) && isWordRune(text[i]) {
+		i++
+	}
+	return i
+}
+
+func (input *InputLayer) browseHistory(step int) bool {
+	if !input.historyOn {
+		return false
+	}
+	pos := input.historyPos + step
+	if pos < 0 || pos > len(input.history) {
+		return true
+	}
+	if input.historyPos == len(input.history) {
+		input.historyEdit = input.text
+	}
+	input.historyPos = pos
+	if pos == len(input.history) {
+		input.text = input.historyEdit
+	} else {
+		input.text = []rune(input.history[pos])
+	}
+	input.cursor = len(input.text)
+	input.changed()
+	return true
+}
+
+func (input *InputLayer) submit() bool {
+	if input.onSubmit == nil && !input.historyOn {
+		return false
+	}
+	text := string(input.text)
+	if input.historyOn && text != "" {
+		n := len(input.history)
+		if n == 0 || input.history[n-1] != text {
+			input.history = append(input.history, text)
+		}
+		input.historyPos = len(input.history)
+		input.historyEdit = nil
+	}
+	if input.onSubmit != nil {
+		input.onSubmit(text)
+	}
+	return true
+}
+
+func (input *InputLayer) HandleKey(e term.Event) bool {
+	alt := e.Mod&term.ModAlt != 0
+	if alt {
+		switch {
+		case e.Ch == 'b' || e.Key == term.KeyArrowLeft:
+			input.cursor = wordLeft(input.text, input.cursor)
+		case e.Ch == 'f' || e.Key == term.KeyArrowRight:
+			input.cursor = wordRight(input.text, input.cursor)
+		case e.Ch == 'd':
+			input.delete(wordRight(input.text, input.cursor))
+		case e.Key == term.KeyBackspace || e.Key == term.KeyBackspace2:
+			input.delete(wordLeft(input.text, input.cursor))
+		default:
+			return false
+		}
+		return true
+	}
+	if e.Ch != 0 {
+		input.insert(e.Ch)
+		return true
+	}
+	switch e.Key {
+	case term.KeySpace:
+		input.insert(' ')
+	case term.KeyBackspace, term.KeyBackspace2:
+		input.delete(input.cursor - 1)
+	case term.KeyDelete, term.KeyCtrlD:
+		input.delete(input.cursor + 1)
+	case term.KeyArrowLeft, term.KeyCtrlB:
+		input.cursor = max(0, input.cursor-1)
+	case term.KeyArrowRight, term.KeyCtrlF:
+		input.cursor = min(len(input.text), input.cursor+1)
+	case term.KeyHome, term.KeyCtrlA:
+		input.cursor = 0
+	case term.KeyEnd, term.KeyCtrlE:
+		input.cursor = len(input.text)
+	case term.KeyCtrlW:
+		input.delete(wordLeft(input.text, input.cursor))
+	case term.KeyCtrlU:
+		input.delete(0)
+	case term.KeyCtrlK:
+		input.delete(len(input.text))
+	case term.KeyArrowUp:
+		return input.browseHistory(-1)
+	case term.KeyArrowDown:
+		return input.browseHistory(1)
+	case term.KeyEnter:
+		return input.submit()
+	default:
+		return false
+	}
+	return true
+}
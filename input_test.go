@@ -0,0 +1,95 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"strings"
+	"testing"
+)
+
+func typeKeys(handler KeyHandler, s string) {
+	for _, ch := range s {
+		handler.HandleKey(term.Event{Type: term.EventKey, Ch: ch})
+	}
+}
+
+func pressKey(handler KeyHandler, key term.Key, mod term.Modifier) bool {
+	return handler.HandleKey(term.Event{Type: term.EventKey, Key: key, Mod: mod})
+}
+
+func TestInputEditing(t *testing.T) {
+	input := Input()
+	typeKeys(input, "hello world")
+	pressKey(input, term.KeyCtrlW, 0)
+	if input.Text() != "hello " {
+		t.Errorf("ctrl-w: %q", input.Text())
+	}
+	typeKeys(input, "there")
+	input.HandleKey(term.Event{Type: term.EventKey, Ch: 'b', Mod: term.ModAlt})
+	pressKey(input, term.KeyBackspace2, 0)
+	if input.Text() != "hellothere" || input.Cursor() != 5 {
+		t.Errorf("backspace: %q at %d", input.Text(), input.Cursor())
+	}
+	pressKey(input, term.KeySpace, 0)
+	pressKey(input, term.KeyCtrlK, 0)
+	if input.Text() != "hello " {
+		t.Errorf("ctrl-k: %q", input.Text())
+	}
+	if pressKey(input, term.KeyEnter, 0) {
+		t.Errorf("enter should pass without a submit handler")
+	}
+}
+
+func TestInputHistory(t *testing.T) {
+	var submitted []string
+	input := Input().EnableHistory().OnSubmit(func(text string) {
+		submitted = append(submitted, text)
+	})
+	for _, line := range []string{"one", "two"} {
+		typeKeys(input, line)
+		pressKey(input, term.KeyEnter, 0)
+		input.SetText("")
+	}
+	typeKeys(input, "dra")
+	pressKey(input, term.KeyArrowUp, 0)
+	pressKey(input, term.KeyArrowUp, 0)
+	pressKey(input, term.KeyArrowUp, 0)
+	if input.Text() != "one" {
+		t.Errorf("history up: %q", input.Text())
+	}
+	pressKey(input, term.KeyArrowDown, 0)
+	pressKey(input, term.KeyArrowDown, 0)
+	if input.Text() != "dra" || len(submitted) != 2 {
+		t.Errorf("history down: %q, %v", input.Text(), submitted)
+	}
+}
+
+func TestInputRender(t *testing.T) {
+	input := Input().SetMask('*')
+	input.SetFocused(true)
+	typeKeys(input, strings.Repeat("x", 15))
+	canvas := NewStringCanvas(10, 1)
+	input.Render(canvas)
+	if got := canvas.String(); got != "********* \n" {
+		t.Errorf("masked input scrolled to the end: %q", got)
+	}
+	pressKey(input, term.KeyHome, 0)
+	input.Render(canvas)
+	if input.Cursor() != 0 || input.offset != 0 {
+		t.Errorf("after Home: cursor %d, offset %d", input.Cursor(), input.offset)
+	}
+	if got := canvas.String(); got != "**********\n" {
+		t.Errorf("input scrolled back to the start: %q", got)
+	}
+	pressKey(input, term.KeyEnd, 0)
+	pressKey(input, term.KeyArrowLeft, 0)
+	input.Render(canvas)
+	if input.offset != 5 {
+		t.Errorf("offset = %d", input.offset)
+	}
+
+	placeholder := Input().SetPlaceholder("name")
+	placeholder.Render(canvas)
+	if got := canvas.String(); got != "name      \n" {
+		t.Errorf("placeholder: %q", got)
+	}
+}
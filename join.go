@@ -0,0 +1,272 @@
+package wind
+
+import (
+	"github.com/nvlled/wind/size"
+)
+
+// JoinBorders renders layer with the frames in it sharing their
+// edges. Each frame draws its right and bottom sides on the first
+// column and line past its space, where the frame next to it has
+// its left or top side, and where lines meet they are joined with
+// the junction runes, as ┬ ┴ ├ ┤ and ┼. So frames put side by side
+// in Hlayer and Vlayer, at any depth, look like one frame split
+// into panes.
+//
+// The right and bottom sides of the outermost frames take the
+// last column and line, so layer gets one less of each. Every
+// pane should be framed, or the side of its neighbour covers
+// its first column or line.
+//
+// The frames in layer count only their left and top sides in their
+// size, as they draw the other two in the space of their neighbour.
+// A frame takes that size when it is put in layer and gives it up
+// when it is drawn outside of any JoinBorders.
+func JoinBorders(layer Layer) Layer {
+	j := &joinLayer{wrapNil(layer)}
+	if markJoined(j.layer) {
+		ClearCache(j.layer)
+	}
+	return j
+}
+
+type joinLayer struct {
+	layer Layer
+}
+
+// markJoined tells the frames under layer that they share their
+// sides, and reports whether some of them did not know yet
+func markJoined(layer Layer) bool {
+	changed := false
+	if frame, ok := layer.(*FrameLayer); ok && !frame.joined {
+		frame.joined, changed = true, true
+	}
+	if container, ok := layer.(Container); ok {
+		for _, child := range container.Children() {
+			if child != nil && markJoined(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func (j *joinLayer) Width() size.T  { return j.layer.Width().Add(size.Const(1)) }
+func (j *joinLayer) Height() size.T { return j.layer.Height().Add(size.Const(1)) }
+
+func (j *joinLayer) Children() []Layer { return []Layer{j.layer} }
+
+func (j *joinLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	if w <= 0 || h <= 0 {
+		return
+	}
+	// frames made since the last render, as by Defer
+	if markJoined(j.layer) {
+		ClearCache(j.layer)
+	}
+	baseX, baseY := canvas.Base()
+	grid := &joinGrid{
+		cells:  make(map[[2]int]*joinCell),
+		bounds: rect{baseX, baseY, w, h},
+	}
+	RenderChild(j.layer, &joinCanvas{canvas.New(0, 0, w-1, h-1), grid})
+
+	for pos, cell := range grid.cells {
+		canvas.Draw(pos[0]-baseX, pos[1]-baseY, cell.rune(), cell.fg, cell.bg)
+	}
+}
+
+// joinCanvas lets the frames find the grid of the JoinBorders
+// they are in.
+type joinCanvas struct {
+	Canvas
+	grid *joinGrid
+}
+
+func (canvas *joinCanvas) unwrap() Canvas { return canvas.Canvas }
+
+func (canvas *joinCanvas) New(x, y, width, height int) Canvas {
+	return &joinCanvas{canvas.Canvas.New(x, y, width, height), canvas.grid}
+}
+
+// the arms of a box drawing rune
+const (
+	armUp = 1 << iota
+	armRight
+	armDown
+	armLeft
+)
+
+// joinGrid has the lines drawn by the frames, by screen position
+type joinGrid struct {
+	cells map[[2]int]*joinCell
+
+	// where the JoinBorders is on the screen
+	bounds rect
+	// where the lines of the frame being added can show
+	clip rect
+}
+
+type joinCell struct {
+	arms   int
+	style  BorderStyle
+	fg, bg uint16
+
+	// a rune of a title, drawn instead of the lines
+	text rune
+}
+
+func (grid *joinGrid) cell(x, y int) *joinCell {
+	cell, ok := grid.cells[[2]int{x, y}]
+	if !ok {
+		cell = &joinCell{}
+		grid.cells[[2]int{x, y}] = cell
+	}
+	return cell
+}
+
+// clipTo keeps the lines added next inside what canvas shows
+// of the JoinBorders, as a frame in a Scroll shows only in the
+// window of the scroll.
+func (grid *joinGrid) clipTo(canvas Canvas) {
+	grid.clip = grid.bounds
+	for c := canvas; ; {
+		scroll, ok := findCanvas[*scrollCanvas](c)
+		if !ok {
+			break
+		}
+		x, y := scroll.view.Base()
+		w, h := scroll.view.Dimension()
+		grid.clip = grid.clip.intersect(rect{x, y, w, h})
+		c = scroll.view
+	}
+}
+
+func (grid *joinGrid) add(x, y, arms int, style BorderStyle, fg, bg uint16) {
+	if !grid.clip.contains(x, y) {
+		return
+	}
+	cell := grid.cell(x, y)
+	cell.arms |= arms
+	cell.style, cell.fg, cell.bg = style, fg, bg
+}
+
+func (grid *joinGrid) hline(x0, x1, y int, style BorderStyle, fg, bg uint16) {
+	for x := x0; x <= x1; x++ {
+		arms := 0
+		if x > x0 {
+			arms |= armLeft
+		}
+		if x < x1 {
+			arms |= armRight
+		}
+		grid.add(x, y, arms, style, fg, bg)
+	}
+}
+
+func (grid *joinGrid) vline(x, y0, y1 int, style BorderStyle, fg, bg uint16) {
+	for y := y0; y <= y1; y++ {
+		arms := 0
+		if y > y0 {
+			arms |= armUp
+		}
+		if y < y1 {
+			arms |= armDown
+		}
+		grid.add(x, y, arms, style, fg, bg)
+	}
+}
+
+func (grid *joinGrid) drawText(x, y int, text []rune, fg, bg uint16) {
+	for i, ch := range text {
+		if !grid.clip.contains(x+i, y) {
+			continue
+		}
+		cell := grid.cell(x+i, y)
+		cell.text, cell.fg, cell.bg = ch, fg, bg
+	}
+}
+
+type junctions struct {
+	teeDown, teeUp, teeRight, teeLeft, cross rune
+}
+
+var (
+	singleJunctions = junctions{'┬', '┴', '├', '┤', '┼'}
+	doubleJunctions = junctions{'╦', '╩', '╠', '╣', '╬'}
+	heavyJunctions  = junctions{'┳', '┻', '┣', '┫', '╋'}
+	asciiJunctions  = junctions{'+', '+', '+', '+', '+'}
+)
+
+func (cell *joinCell) rune() rune {
+	if cell.text != 0 {
+		return cell.text
+	}
+	s := cell.style
+	j := singleJunctions
+	switch s.Horizontal {
+	case DoubleBorder.Horizontal:
+		j = doubleJunctions
+	case HeavyBorder.Horizontal:
+		j = heavyJunctions
+	case ASCIIBorder.Horizontal:
+		j = asciiJunctions
+	}
+	switch cell.arms {
+	case armRight | armDown:
+		return s.TopLeft
+	case armLeft | armDown:
+		return s.TopRight
+	case armRight | armUp:
+		return s.BottomLeft
+	case armLeft | armUp:
+		return s.BottomRight
+	case armLeft | armRight | armDown:
+		return j.teeDown
+	case armLeft | armRight | armUp:
+		return j.teeUp
+	case armUp | armDown | armRight:
+		return j.teeRight
+	case armUp | armDown | armLeft:
+		return j.teeLeft
+	case armUp | armDown | armLeft | armRight:
+		return j.cross
+	case armUp, armDown, armUp | armDown:
+		return s.Vertical
+	}
+	return s.Horizontal
+}
+
+// renderJoined is Render for a frame inside JoinBorders
+func (frame *FrameLayer) renderJoined(canvas Canvas, grid *joinGrid) {
+	w, h := canvas.Dimension()
+	top, _, _, left := frame.insets()
+	s, fg, bg := frame.style, frame.fg, frame.bg
+	x0, y0 := canvas.Base()
+	x1, y1 := x0+w, y0+h
+	grid.clipTo(canvas)
+
+	if s.Top {
+		grid.hline(x0, x1, y0, s, fg, bg)
+	}
+	if s.Bottom {
+		grid.hline(x0, x1, y1, s, fg, bg)
+	}
+	if s.Left {
+		grid.vline(x0, y0, y1, s, fg, bg)
+	}
+	if s.Right {
+		grid.vline(x1, y0, y1, s, fg, bg)
+	}
+	// the side is one longer than canvas, with the outer corner
+	if s.Top && frame.title != "" {
+		x, label := frame.label(w+1, frame.title, frame.titleJustify)
+		grid.drawText(x0+x, y0, label, fg, bg)
+	}
+	if s.Bottom && frame.footer != "" {
+		x, label := frame.label(w+1, frame.footer, frame.footerJustify)
+		grid.drawText(x0+x, y1, label, fg, bg)
+	}
+
+	RenderChild(frame.layer, canvas.New(left, top, max(0, w-left), max(0, h-top)))
+}
@@ -0,0 +1,84 @@
+package wind
+
+import (
+	"github.com/nvlled/wind/size"
+	"testing"
+)
+
+func TestJoinBorders(t *testing.T) {
+	pane := func() Layer { return Frame(SingleBorder, Text(" ")) }
+	layer := JoinBorders(Vlayer(
+		Hlayer(pane(), pane()),
+		Hlayer(pane(), pane()),
+	))
+	if layer.Width() != size.Const(5) || layer.Height() != size.Const(5) {
+		t.Errorf("size %v x %v, want the 5 x 5 that is drawn", layer.Width(), layer.Height())
+	}
+	canvas := NewStringCanvas(5, 5)
+	layer.Render(canvas)
+	want := "┌─┬─┐\n" +
+		"│ │ │\n" +
+		"├─┼─┤\n" +
+		"│ │ │\n" +
+		"└─┴─┘\n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
+
+func TestJoinBordersTitles(t *testing.T) {
+	layer := JoinBorders(Hlayer(
+		Frame(DoubleBorder, Text("abcde")).SetTitle("x", JustifyLeft),
+		Frame(DoubleBorder, RenderLayer(func(Canvas) {})).SetFooter("y", JustifyRight),
+	))
+	canvas := NewStringCanvas(11, 3)
+	layer.Render(canvas)
+	want := "╔ x ══╦═══╗\n" +
+		"║abcde║   ║\n" +
+		"╚═════╩ y ╝\n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
+
+func TestJoinBordersInScroll(t *testing.T) {
+	pane := func(text string) Layer { return Frame(SingleBorder, Text(text)) }
+	scroll := Scroll(Vlayer(pane("1"), pane("2"), pane("3"))).ScrollTo(0, 2)
+	layer := JoinBorders(Vlayer(SizeH(3, scroll), pane("x")))
+	canvas := NewStringCanvas(4, 6)
+	layer.Render(canvas)
+	want := "├─┤ \n" +
+		"│2│ \n" +
+		"├─┤ \n" +
+		"┌─┐ \n" +
+		"│x│ \n" +
+		"└─┘ \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
+
+func TestJoinBordersFrameChanges(t *testing.T) {
+	var later Layer
+	layer := JoinBorders(Hlayer(Defer(func() Layer { return later }), Frame(SingleBorder, Text(" "))))
+	later = Frame(SingleBorder, Text(" "))
+	canvas := NewStringCanvas(7, 3)
+	layer.Render(canvas)
+	want := "┌─┬─┐  \n" +
+		"│ │ │  \n" +
+		"└─┴─┘  \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("frame made after JoinBorders:\n%s", got)
+	}
+
+	row := Hlayer(later, Text("|"))
+	row.Render(NewStringCanvas(4, 3))
+	canvas = NewStringCanvas(4, 3)
+	row.Render(canvas)
+	want = "┌─┐|\n" +
+		"│ │ \n" +
+		"└─┘ \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("frame taken out of JoinBorders:\n%s", got)
+	}
+}
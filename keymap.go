@@ -0,0 +1,389 @@
+package wind
+
+import (
+	"fmt"
+	term "github.com/nsf/termbox-go"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type keyPress struct {
+	key term.Key
+	ch  rune
+	mod term.Modifier
+}
+
+type Binding struct {
+	Keys   string
+	Name   string
+	action func()
+	seq    []keyPress
+}
+
+// Keymap binds keys and key sequences to named actions.
+// A sequence is written as space separated keys, like "g g"
+// or "Ctrl-x Ctrl-s". Keys are single characters, names like
+// Enter, Esc, Tab, Space, Backspace, Delete, Insert, Home, End,
+// PgUp, PgDn, Up, Down, Left, Right and F1 to F12, or any of
+// those with a Ctrl- or Alt- prefix. Ctrl-h is Backspace, as
+// terminals send the same key for both.
+//
+// A Keymap is itself a key handler. Give it to App.SetKeymap for
+// global bindings, or wrap a layer with WithKeymap for bindings
+// that only apply while the focus is inside that layer.
+type Keymap struct {
+	bindings []*Binding
+	pending  []keyPress
+	deadline time.Time
+
+	// how long to wait for the next key of a sequence
+	Timeout time.Duration
+}
+
+func NewKeymap() *Keymap {
+	return &Keymap{Timeout: time.Second}
+}
+
+// WithKeymap gives layer the bindings of km. An App
+// also times out the pending sequences of km.
+func WithKeymap(layer Layer, km *Keymap) Layer {
+	return &keymapLayer{keyLayer{layer, km.HandleKey}, km}
+}
+
+type keymapLayer struct {
+	keyLayer
+	keymap *Keymap
+}
+
+// keymaps returns the keymaps given with WithKeymap
+// to layer and the layers under it, shown or not
+func keymaps(layer Layer) []*Keymap {
+	var result []*Keymap
+	if k, ok := layer.(*keymapLayer); ok {
+		result = append(result, k.keymap)
+	}
+	if container, ok := layer.(Container); ok {
+		for _, child := range container.Children() {
+			if child != nil {
+				result = append(result, keymaps(child)...)
+			}
+		}
+	}
+	return result
+}
+
+// Bind replaces any binding for the same keys.
+func (km *Keymap) Bind(keys, name string, action func()) error {
+	seq, err := parseKeys(keys)
+	if err != nil {
+		return err
+	}
+	if action == nil {
+		return fmt.Errorf("keymap: no action for %q", keys)
+	}
+	b := &Binding{strings.Join(strings.Fields(keys), " "), name, action, seq}
+	for i, other := range km.bindings {
+		if sameKeys(other.seq, seq) {
+			km.bindings[i] = b
+			return nil
+		}
+	}
+	km.bindings = append(km.bindings, b)
+	return nil
+}
+
+// MustBind is Bind for keys that are known to be valid.
+func (km *Keymap) MustBind(keys, name string, action func()) *Keymap {
+	if err := km.Bind(keys, name, action); err != nil {
+		panic(err)
+	}
+	return km
+}
+
+func (km *Keymap) Bindings() []Binding {
+	var result []Binding
+	for _, b := range km.bindings {
+		result = append(result, *b)
+	}
+	return result
+}
+
+// Pending returns the keys typed so far of an unfinished sequence.
+func (km *Keymap) Pending() string {
+	var names []string
+	for _, press := range km.pending {
+		names = append(names, press.String())
+	}
+	return strings.Join(names, " ")
+}
+
+// Deadline is when the pending sequence times out.
+func (km *Keymap) Deadline() (time.Time, bool) {
+	return km.deadline, len(km.pending) > 0
+}
+
+// Expire drops a pending sequence that timed out. If the keys so
+// far are bound on their own, as "g" is when "g g" is also bound,
+// their action runs. Returns whether an action ran.
+func (km *Keymap) Expire(now time.Time) bool {
+	if len(km.pending) == 0 || now.Before(km.deadline) {
+		return false
+	}
+	return km.flush()
+}
+
+func (km *Keymap) flush() bool {
+	exact, _ := km.lookup(km.pending)
+	km.pending = nil
+	if exact != nil {
+		exact.action()
+		return true
+	}
+	return false
+}
+
+func (km *Keymap) HandleKey(e term.Event) bool {
+	km.Expire(time.Now())
+	seq := append(append([]keyPress{}, km.pending...), eventKey(e))
+	exact, prefix := km.lookup(seq)
+	switch {
+	case prefix:
+		km.pending = seq
+		km.deadline = time.Now().Add(km.Timeout)
+		return true
+	case exact != nil:
+		km.pending = nil
+		exact.action()
+		return true
+	case len(km.pending) > 0:
+		// the sequence is broken, finish what was
+		// typed before and start over with this key
+		km.flush()
+		return km.HandleKey(e)
+	}
+	return false
+}
+
+// lookup finds the binding for seq and tells whether
+// seq is the start of a longer binding.
+func (km *Keymap) lookup(seq []keyPress) (exact *Binding, prefix bool) {
+	for _, b := range km.bindings {
+		if len(b.seq) < len(seq) || !sameKeys(b.seq[:len(seq)], seq) {
+			continue
+		}
+		if len(b.seq) == len(seq) {
+			exact = b
+		} else {
+			prefix = true
+		}
+	}
+	return exact, prefix
+}
+
+func sameKeys(a, b []keyPress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func eventKey(e term.Event) keyPress {
+	mod := e.Mod & term.ModAlt
+	switch {
+	case e.Ch == ' ':
+		return keyPress{key: term.KeySpace, mod: mod}
+	case e.Ch != 0:
+		return keyPress{ch: e.Ch, mod: mod}
+	}
+	return keyPress{key: normalKey(e.Key), mod: mod}
+}
+
+// normalKey gives Backspace one key. Terminals send either
+// KeyBackspace, which is also Ctrl-h, or KeyBackspace2,
+// so a binding for Ctrl-h is a binding for Backspace.
+func normalKey(key term.Key) term.Key {
+	if key == term.KeyBackspace {
+		return term.KeyBackspace2
+	}
+	return key
+}
+
+var keyNames = map[string]term.Key{
+	"enter":     term.KeyEnter,
+	"esc":       term.KeyEsc,
+	"escape":    term.KeyEsc,
+	"tab":       term.KeyTab,
+	"space":     term.KeySpace,
+	"backspace": term.KeyBackspace2,
+	"delete":    term.KeyDelete,
+	"del":       term.KeyDelete,
+	"insert":    term.KeyInsert,
+	"home":      term.KeyHome,
+	"end":       term.KeyEnd,
+	"pgup":      term.KeyPgup,
+	"pageup":    term.KeyPgup,
+	"pgdn":      term.KeyPgdn,
+	"pagedown":  term.KeyPgdn,
+	"up":        term.KeyArrowUp,
+	"down":      term.KeyArrowDown,
+	"left":      term.KeyArrowLeft,
+	"right":     term.KeyArrowRight,
+	"f1":        term.KeyF1,
+	"f2":        term.KeyF2,
+	"f3":        term.KeyF3,
+	"f4":        term.KeyF4,
+	"f5":        term.KeyF5,
+	"f6":        term.KeyF6,
+	"f7":        term.KeyF7,
+	"f8":        term.KeyF8,
+	"f9":        term.KeyF9,
+	"f10":       term.KeyF10,
+	"f11":       term.KeyF11,
+	"f12":       term.KeyF12,
+}
+
+func parseKeys(keys string) ([]keyPress, error) {
+	var seq []keyPress
+	for _, field := range strings.Fields(keys) {
+		press, err := parseKey(field)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, press)
+	}
+	if len(seq) == 0 {
+		return nil, fmt.Errorf("keymap: no keys given")
+	}
+	return seq, nil
+}
+
+func parseKey(s string) (keyPress, error) {
+	var press keyPress
+	ctrl := false
+	name := s
+	for {
+		lower := strings.ToLower(name)
+		if len(name) > 2 && (strings.HasPrefix(lower, "ctrl-") || strings.HasPrefix(lower, "c-")) {
+			ctrl = true
+			name = name[strings.Index(name, "-")+1:]
+		} else if len(name) > 2 && (strings.HasPrefix(lower, "alt-") || strings.HasPrefix(lower, "m-")) {
+			press.mod |= term.ModAlt
+			name = name[strings.Index(name, "-")+1:]
+		} else {
+			break
+		}
+	}
+
+	if key, ok := keyNames[strings.ToLower(name)]; ok && utf8.RuneCountInString(name) > 1 {
+		if ctrl && key != term.KeySpace {
+			return press, fmt.Errorf("keymap: no ctrl key for %q", s)
+		}
+		press.key = key
+		if ctrl {
+			press.key = term.KeyCtrlSpace
+		}
+		return press, nil
+	}
+	if utf8.RuneCountInString(name) != 1 {
+		return press, fmt.Errorf("keymap: unknown key %q", s)
+	}
+	ch, _ := utf8.DecodeRuneInString(name)
+	switch {
+	case ctrl && ch >= 'a' && ch <= 'z':
+		press.key = normalKey(term.KeyCtrlA + term.Key(ch-'a'))
+	case ctrl && ch >= 'A' && ch <= 'Z':
+		press.key = normalKey(term.KeyCtrlA + term.Key(ch-'A'))
+	case ctrl:
+		return press, fmt.Errorf("keymap: no ctrl key for %q", s)
+	case ch == ' ':
+		press.key = term.KeySpace
+	default:
+		press.ch = ch
+	}
+	return press, nil
+}
+
+func (press keyPress) String() string {
+	s := ""
+	if press.mod&term.ModAlt != 0 {
+		s = "Alt-"
+	}
+	if press.ch != 0 {
+		return s + string(press.ch)
+	}
+	if name, ok := keyDisplayNames[press.key]; ok {
+		return s + name
+	}
+	if press.key >= term.KeyCtrlA && press.key <= term.KeyCtrlZ {
+		return s + "Ctrl-" + string(rune('a'+press.key-term.KeyCtrlA))
+	}
+	return s + fmt.Sprintf("Key(%d)", press.key)
+}
+
+var keyDisplayNames = map[term.Key]string{
+	term.KeyCtrlSpace:  "Ctrl-Space",
+	term.KeyEnter:      "Enter",
+	term.KeyEsc:        "Esc",
+	term.KeyTab:        "Tab",
+	term.KeySpace:      "Space",
+	term.KeyBackspace2: "Backspace",
+	term.KeyDelete:     "Delete",
+	term.KeyInsert:     "Insert",
+	term.KeyHome:       "Home",
+	term.KeyEnd:        "End",
+	term.KeyPgup:       "PgUp",
+	term.KeyPgdn:       "PgDn",
+	term.KeyArrowUp:    "Up",
+	term.KeyArrowDown:  "Down",
+	term.KeyArrowLeft:  "Left",
+	term.KeyArrowRight: "Right",
+	term.KeyF1:         "F1",
+	term.KeyF2:         "F2",
+	term.KeyF3:         "F3",
+	term.KeyF4:         "F4",
+	term.KeyF5:         "F5",
+	term.KeyF6:         "F6",
+	term.KeyF7:         "F7",
+	term.KeyF8:         "F8",
+	term.KeyF9:         "F9",
+	term.KeyF10:        "F10",
+	term.KeyF11:        "F11",
+	term.KeyF12:        "F12",
+}
+
+// Help returns a one line summary of the bindings,
+// for the bottom of a Vlayer.
+func (km *Keymap) Help() Layer {
+	return SizeH(1, RenderLayer(func(canvas Canvas) {
+		x := 0
+		for _, b := range km.bindings {
+			canvas.DrawText(x, 0, b.Keys, uint16(term.AttrBold), 0)
+			x += utf8.RuneCountInString(b.Keys) + 1
+			canvas.DrawText(x, 0, b.Name, 0, 0)
+			x += utf8.RuneCountInString(b.Name) + 2
+		}
+	}))
+}
+
+// HelpTable returns the bindings with their names in two columns.
+func (km *Keymap) HelpTable() Layer {
+	var keys, names []Layer
+	for _, b := range km.bindings {
+		keys = append(keys, SetColor(uint16(term.AttrBold), 0, TextLine(b.Keys)))
+		names = append(names, TextLine(b.Name))
+	}
+	keyColumn := Vlayer(keys...)
+	w := 0
+	for _, b := range km.bindings {
+		if n := utf8.RuneCountInString(b.Keys); n > w {
+			w = n
+		}
+	}
+	return Hlayer(SizeW(w+2, keyColumn), Vlayer(names...))
+}
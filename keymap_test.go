@@ -0,0 +1,95 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+	"time"
+)
+
+func TestKeymap(t *testing.T) {
+	var actions []string
+	action := func(name string) func() {
+		return func() { actions = append(actions, name) }
+	}
+	km := NewKeymap().
+		MustBind("g", "next", action("g")).
+		MustBind("g g", "top", action("gg")).
+		MustBind("Ctrl-x Ctrl-s", "save", action("save")).
+		MustBind("Alt-Enter", "run", action("run"))
+	if err := km.Bind("Ctrl-Enter", "bad", nil); err == nil {
+		t.Error("expected an error for Ctrl-Enter")
+	}
+	if err := km.Bind("x", "nothing", nil); err == nil {
+		t.Error("expected an error for a nil action")
+	}
+
+	key := func(ch rune) term.Event { return term.Event{Type: term.EventKey, Ch: ch} }
+	km.HandleKey(key('g'))
+	if km.Pending() != "g" || len(actions) != 0 {
+		t.Fatalf("g should wait for the next key")
+	}
+	km.HandleKey(key('g'))
+	km.HandleKey(term.Event{Type: term.EventKey, Key: term.KeyCtrlX})
+	km.HandleKey(term.Event{Type: term.EventKey, Key: term.KeyCtrlS})
+	km.HandleKey(term.Event{Type: term.EventKey, Key: term.KeyEnter, Mod: term.ModAlt})
+	km.HandleKey(key('g'))
+	if handled := km.HandleKey(key('z')); handled {
+		t.Error("z is not bound")
+	}
+	km.HandleKey(key('g'))
+	if km.Expire(time.Now()) {
+		t.Error("g should not expire before the timeout")
+	}
+	if deadline, ok := km.Deadline(); !ok || !km.Expire(deadline) {
+		t.Error("g should expire at the deadline")
+	}
+
+	want := []string{"gg", "save", "run", "g", "g"}
+	if len(actions) != len(want) {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("actions = %v, want %v", actions, want)
+		}
+	}
+	if b := km.Bindings()[2]; b.Keys != "Ctrl-x Ctrl-s" || b.Name != "save" {
+		t.Errorf("unexpected binding: %v", b)
+	}
+
+	canvas := NewStringCanvas(40, 1)
+	km.Help().Render(canvas)
+	if got := canvas.String(); got[:14] != "g next  g g to" {
+		t.Errorf("unexpected help line: %q", got)
+	}
+}
+
+func TestKeymapBackspace(t *testing.T) {
+	var actions []string
+	km := NewKeymap().MustBind("Ctrl-h", "back", func() { actions = append(actions, "back") })
+	km.HandleKey(term.Event{Type: term.EventKey, Key: term.KeyBackspace})
+	km.HandleKey(term.Event{Type: term.EventKey, Key: term.KeyBackspace2})
+	if len(actions) != 2 {
+		t.Errorf("Ctrl-h should take both backspace keys: %v", actions)
+	}
+}
+
+func TestScopedKeymapDeadline(t *testing.T) {
+	km := NewKeymap().MustBind("x x", "cut", func() {})
+	app := NewApp(Vlayer(WithKeymap(newTestFocusable("a"), km)))
+	if _, ok := app.keymapDeadline(); ok {
+		t.Fatalf("nothing is pending")
+	}
+	app.handleKey(term.Event{Type: term.EventKey, Ch: 'x'})
+	deadline, ok := app.keymapDeadline()
+	if !ok || km.Pending() != "x" {
+		t.Fatalf("the app should wait for the scoped keymap")
+	}
+	for _, km := range app.keymaps() {
+		km.Expire(deadline)
+	}
+	if km.Pending() != "" {
+		t.Errorf("the pending x should expire")
+	}
+	app.Quit()
+}
@@ -0,0 +1,211 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"sort"
+)
+
+// ListSource gives the items of a ListLayer.
+// Only the items in view are asked for.
+type ListSource interface {
+	Len() int
+	Item(i int) string
+}
+
+type StringItems []string
+
+func (items StringItems) Len() int          { return len(items) }
+func (items StringItems) Item(i int) string { return items[i] }
+
+func ListFunc(count func() int, item func(i int) string) ListSource {
+	return &listFunc{count, item}
+}
+
+type listFunc struct {
+	count func() int
+	item  func(i int) string
+}
+
+func (l *listFunc) Len() int          { return l.count() }
+func (l *listFunc) Item(i int) string { return l.item(i) }
+
+// ListLayer shows items one per line with one of them selected.
+// Up, Down, PgUp, PgDn, Home and End, or k, j, g and G move the
+// selection, Enter submits it. With multi select on, Space marks
+// and unmarks the selected item. Clicking selects an item and the
+// wheel scrolls.
+type ListLayer struct {
+	source   ListSource
+	selected int
+	offset   int
+	multi    bool
+	marked   map[int]bool
+	focused  bool
+	height   int
+
+	onChange func(index int)
+	onSubmit func(index int)
+}
+
+func List(source ListSource) *ListLayer {
+	return &ListLayer{
+		source: source,
+		marked: make(map[int]bool),
+		height: 1,
+	}
+}
+
+func (list *ListLayer) Source() ListSource { return list.source }
+func (list *ListLayer) Selected() int      { return list.selected }
+
+func (list *ListLayer) SetSource(source ListSource) *ListLayer {
+	list.source = source
+	list.marked = make(map[int]bool)
+	return list.Select(list.selected)
+}
+
+// Select changes the selection, clamped to the items.
+func (list *ListLayer) Select(index int) *ListLayer {
+	index = clamp(index, 0, max(0, list.source.Len()-1))
+	if index != list.selected {
+		list.selected = index
+		if list.onChange != nil {
+			list.onChange(index)
+		}
+	}
+	return list
+}
+
+func (list *ListLayer) SetMultiSelect(multi bool) *ListLayer {
+	list.multi = multi
+	return list
+}
+
+func (list *ListLayer) Toggle(index int) *ListLayer {
+	if list.marked[index] {
+		delete(list.marked, index)
+	} else {
+		list.marked[index] = true
+	}
+	return list
+}
+
+// Marked returns the marked items in order.
+func (list *ListLayer) Marked() []int {
+	var result []int
+	for i := range list.marked {
+		result = append(result, i)
+	}
+	sort.Ints(result)
+	return result
+}
+
+func (list *ListLayer) OnChange(handler func(index int)) *ListLayer {
+	list.onChange = handler
+	return list
+}
+
+func (list *ListLayer) OnSubmit(handler func(index int)) *ListLayer {
+	list.onSubmit = handler
+	return list
+}
+
+func (list *ListLayer) Width() size.T  { return size.Free }
+func (list *ListLayer) Height() size.T { return size.Free }
+
+func (list *ListLayer) SetFocused(focused bool) { list.focused = focused }
+
+func (list *ListLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	if h <= 0 {
+		return
+	}
+	list.height = h
+	n := list.source.Len()
+	list.selected = clamp(list.selected, 0, max(0, n-1))
+	list.offset = clamp(list.offset, list.selected-h+1, list.selected)
+	list.offset = clamp(list.offset, 0, max(0, n-h))
+
+	for y := 0; y < h; y++ {
+		i := list.offset + y
+		var fg uint16
+		text := ""
+		if i < n {
+			text = list.source.Item(i)
+			if list.multi {
+				if list.marked[i] {
+					text = "[x] " + text
+				} else {
+					text = "[ ] " + text
+				}
+			}
+			if i == list.selected {
+				fg = uint16(term.AttrBold)
+				if list.focused {
+					fg = uint16(term.AttrReverse)
+				}
+			}
+		}
+		x := 0
+		for _, ch := range text {
+			if x >= w {
+				break
+			}
+			canvas.Draw(x, y, ch, fg, 0)
+			x++
+		}
+		for ; x < w; x++ {
+			canvas.Draw(x, y, ' ', fg, 0)
+		}
+	}
+}
+
+func (list *ListLayer) HandleKey(e term.Event) bool {
+	page := max(1, list.height-1)
+	switch {
+	case e.Key == term.KeyArrowUp || e.Ch == 'k':
+		list.Select(list.selected - 1)
+	case e.Key == term.KeyArrowDown || e.Ch == 'j':
+		list.Select(list.selected + 1)
+	case e.Key == term.KeyPgup:
+		list.Select(list.selected - page)
+	case e.Key == term.KeyPgdn:
+		list.Select(list.selected + page)
+	case e.Key == term.KeyHome || e.Ch == 'g':
+		list.Select(0)
+	case e.Key == term.KeyEnd || e.Ch == 'G':
+		list.Select(list.source.Len() - 1)
+	case e.Key == term.KeySpace && list.multi:
+		list.Toggle(list.selected)
+	case e.Key == term.KeyEnter && list.onSubmit != nil:
+		if list.source.Len() > 0 {
+			list.onSubmit(list.selected)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// scrolling the wheel drags the selection
+// along when it leaves the view
+func (list *ListLayer) HandleMouse(e term.Event, x, y int) bool {
+	n := list.source.Len()
+	switch e.Key {
+	case term.MouseLeft:
+		if i := list.offset + y; i < n && e.Mod&term.ModMotion == 0 {
+			list.Select(i)
+		}
+	case term.MouseWheelUp, term.MouseWheelDown:
+		step := 3
+		if e.Key == term.MouseWheelUp {
+			step = -3
+		}
+		list.offset = clamp(list.offset+step, 0, max(0, n-list.height))
+		list.Select(clamp(list.selected, list.offset, list.offset+list.height-1))
+	default:
+		return false
+	}
+	return true
+}
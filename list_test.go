@@ -0,0 +1,64 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"strconv"
+	"testing"
+)
+
+func TestListScrolling(t *testing.T) {
+	var changes []int
+	list := List(StringItems{"a", "b", "c", "d", "e"}).OnChange(func(i int) {
+		changes = append(changes, i)
+	})
+	canvas := NewStringCanvas(3, 2)
+	list.Render(canvas)
+	if got := canvas.String(); got != "a  \nb  \n" {
+		t.Errorf("first render: %q", got)
+	}
+	pressKey(list, term.KeyArrowDown, 0)
+	pressKey(list, term.KeyArrowDown, 0)
+	list.Render(canvas)
+	if got := canvas.String(); got != "b  \nc  \n" {
+		t.Errorf("selection not followed: %q", got)
+	}
+	pressKey(list, term.KeyEnd, 0)
+	pressKey(list, term.KeyArrowDown, 0)
+	if list.Selected() != 4 || len(changes) != 3 {
+		t.Errorf("selected %d, changes %v", list.Selected(), changes)
+	}
+	if pressKey(list, term.KeyEnter, 0) {
+		t.Error("Enter handled without OnSubmit")
+	}
+}
+
+func TestListMouse(t *testing.T) {
+	list := List(ListFunc(func() int { return 1000 }, strconv.Itoa))
+	canvas := NewStringCanvas(4, 3)
+	list.Render(canvas)
+	list.HandleMouse(term.Event{Type: term.EventMouse, Key: term.MouseLeft}, 0, 2)
+	if list.Selected() != 2 {
+		t.Errorf("click selected %d", list.Selected())
+	}
+	list.HandleMouse(term.Event{Type: term.EventMouse, Key: term.MouseWheelDown}, 0, 0)
+	list.Render(canvas)
+	if got := canvas.String(); got != "3   \n4   \n5   \n" || list.Selected() != 3 {
+		t.Errorf("wheel: selected %d, %q", list.Selected(), got)
+	}
+}
+
+func TestListMultiSelect(t *testing.T) {
+	list := List(StringItems{"a", "b", "c"}).SetMultiSelect(true)
+	pressKey(list, term.KeySpace, 0)
+	pressKey(list, term.KeyArrowDown, 0)
+	pressKey(list, term.KeyArrowDown, 0)
+	pressKey(list, term.KeySpace, 0)
+	if got := list.Marked(); len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("marked %v", got)
+	}
+	canvas := NewStringCanvas(5, 3)
+	list.Render(canvas)
+	if got := canvas.String(); got != "[x] a\n[ ] b\n[x] c\n" {
+		t.Errorf("render: %q", got)
+	}
+}
@@ -0,0 +1,302 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type levelColor struct {
+	keyword string
+	fg      uint16
+}
+
+// LogViewLayer shows the last lines of a log, keeping at most a
+// fixed number of them. Lines can be added from any goroutine,
+// and when it is run by an App, the App redraws.
+//
+// It follows the end of the log until it is scrolled up, with the
+// arrow keys, PgUp, Home or the mouse wheel, and follows again when
+// scrolled back to the end, or with End. While it has the focus, a
+// scrollbar in the last column shows where the view is in the log.
+// Lines with a level keyword, like ERROR or WARN, are coloured by
+// their level.
+type LogViewLayer struct {
+	mu        sync.Mutex
+	lines     []string
+	scheduler *Scheduler
+
+	// the kept lines are numbered from first up to next,
+	// line n is at n modulo the capacity
+	first, next int
+	// the numbers of the kept lines that pass the filter
+	matches []int
+
+	follow  bool
+	offset  int
+	height  int
+	focused bool
+
+	filter   string
+	filterRe *regexp.Regexp
+	levels   []levelColor
+}
+
+func LogView(capacity int) *LogViewLayer {
+	return &LogViewLayer{
+		lines:  make([]string, max(capacity, 1)),
+		follow: true,
+		levels: []levelColor{
+			{"ERROR", uint16(term.ColorRed)},
+			{"WARN", uint16(term.ColorYellow)},
+			{"DEBUG", uint16(term.AttrDim)},
+		},
+	}
+}
+
+// Append adds lines at the end, dropping the oldest lines when
+// there are more than the capacity. Text with newlines is split.
+func (view *LogViewLayer) Append(lines ...string) {
+	view.mu.Lock()
+	dropped := 0
+	for _, text := range lines {
+		for _, line := range strings.Split(text, "\n") {
+			if view.next-view.first == len(view.lines) {
+				if len(view.matches) > 0 && view.matches[0] == view.first {
+					view.matches = view.matches[1:]
+					dropped++
+				}
+				view.first++
+			}
+			view.lines[view.next%len(view.lines)] = line
+			if view.match(line) {
+				view.matches = append(view.matches, view.next)
+			}
+			view.next++
+		}
+	}
+	// the shown lines stay in place when the
+	// lines above them that passed the filter go
+	if !view.follow {
+		view.offset = max(0, view.offset-dropped)
+	}
+	scheduler := view.scheduler
+	view.mu.Unlock()
+
+	if scheduler != nil {
+		scheduler.Invalidate(view)
+	}
+}
+
+// Write appends the lines in p, so the log view can
+// be the output of a log.Logger.
+func (view *LogViewLayer) Write(p []byte) (int, error) {
+	view.Append(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+func (view *LogViewLayer) Clear() {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	view.first, view.next, view.offset = 0, 0, 0
+	view.matches = nil
+	view.follow = true
+}
+
+// Lines returns the kept lines that pass the filter.
+func (view *LogViewLayer) Lines() []string {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	return view.filtered()
+}
+
+func (view *LogViewLayer) filtered() []string {
+	return view.matched(0, len(view.matches))
+}
+
+// matched returns the lines that pass the filter from i to j
+func (view *LogViewLayer) matched(i, j int) []string {
+	var lines []string
+	for _, n := range view.matches[i:j] {
+		lines = append(lines, view.lines[n%len(view.lines)])
+	}
+	return lines
+}
+
+func (view *LogViewLayer) match(line string) bool {
+	if view.filterRe != nil {
+		return view.filterRe.MatchString(line)
+	}
+	return strings.Contains(line, view.filter)
+}
+
+// refilter finds the lines that pass a new filter
+func (view *LogViewLayer) refilter() {
+	view.matches = nil
+	for n := view.first; n < view.next; n++ {
+		if view.match(view.lines[n%len(view.lines)]) {
+			view.matches = append(view.matches, n)
+		}
+	}
+}
+
+// SetFilter shows only the lines that contain substring.
+// An empty substring shows all lines.
+func (view *LogViewLayer) SetFilter(substring string) *LogViewLayer {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	view.filter, view.filterRe = substring, nil
+	view.refilter()
+	return view
+}
+
+// SetFilterRegexp shows only the lines that match re.
+// A nil re shows all lines.
+func (view *LogViewLayer) SetFilterRegexp(re *regexp.Regexp) *LogViewLayer {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	view.filter, view.filterRe = "", re
+	view.refilter()
+	return view
+}
+
+// SetLevelColor colours the lines that contain keyword.
+// The keywords are tried in the order they were set,
+// after ERROR, WARN and DEBUG.
+func (view *LogViewLayer) SetLevelColor(keyword string, fg uint16) *LogViewLayer {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	for i, level := range view.levels {
+		if level.keyword == keyword {
+			view.levels[i].fg = fg
+			return view
+		}
+	}
+	view.levels = append(view.levels, levelColor{keyword, fg})
+	return view
+}
+
+func (view *LogViewLayer) levelColor(line string) uint16 {
+	for _, level := range view.levels {
+		if strings.Contains(line, level.keyword) {
+			return level.fg
+		}
+	}
+	return 0
+}
+
+// Following tells whether the view stays at the end of the log.
+func (view *LogViewLayer) Following() bool {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	return view.follow
+}
+
+func (view *LogViewLayer) Follow() *LogViewLayer {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	view.follow = true
+	return view
+}
+
+// scroll moves the view by step lines, and
+// follows the log when it reaches the end
+func (view *LogViewLayer) scroll(step int) {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	bottom := max(0, len(view.matches)-view.height)
+	if view.follow {
+		view.offset = bottom
+	}
+	view.offset = clamp(view.offset+step, 0, bottom)
+	view.follow = view.offset == bottom
+}
+
+func (view *LogViewLayer) Width() size.T  { return size.Free }
+func (view *LogViewLayer) Height() size.T { return size.Free }
+
+func (view *LogViewLayer) SetFocused(focused bool) {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	view.focused = focused
+}
+
+func (view *LogViewLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	view.mu.Lock()
+	if c, ok := findCanvas[*schedulerCanvas](canvas); ok {
+		view.scheduler = c.scheduler
+	}
+	view.height = h
+	bottom := max(0, len(view.matches)-h)
+	if view.follow {
+		view.offset = bottom
+	}
+	view.offset = clamp(view.offset, 0, bottom)
+	lines := view.matched(view.offset, min(view.offset+h, len(view.matches)))
+	var colors []uint16
+	for _, line := range lines {
+		colors = append(colors, view.levelColor(line))
+	}
+	offset, total := view.offset, len(view.matches)
+	scrollbar := view.focused && w > 1
+	view.mu.Unlock()
+
+	if scrollbar {
+		w--
+		drawScrollbar(canvas.New(w, 0, 1, h), true, offset, h, total)
+	}
+
+	for y, line := range lines {
+		x := 0
+		for _, ch := range line {
+			if x >= w {
+				break
+			}
+			canvas.Draw(x, y, ch, colors[y], 0)
+			x++
+		}
+	}
+}
+
+func (view *LogViewLayer) HandleKey(e term.Event) bool {
+	page := max(1, view.height-1)
+	switch e.Key {
+	case term.KeyArrowUp:
+		view.scroll(-1)
+	case term.KeyArrowDown:
+		view.scroll(1)
+	case term.KeyPgup:
+		view.scroll(-page)
+	case term.KeyPgdn:
+		view.scroll(page)
+	case term.KeyHome:
+		view.scroll(-view.Len())
+	case term.KeyEnd:
+		view.Follow()
+	default:
+		return false
+	}
+	return true
+}
+
+// Len is the number of kept lines, filtered or not.
+func (view *LogViewLayer) Len() int {
+	view.mu.Lock()
+	defer view.mu.Unlock()
+	return view.next - view.first
+}
+
+func (view *LogViewLayer) HandleMouse(e term.Event, x, y int) bool {
+	switch e.Key {
+	case term.MouseWheelUp:
+		view.scroll(-3)
+	case term.MouseWheelDown:
+		view.scroll(3)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestLogViewRingBuffer(t *testing.T) {
+	view := LogView(3)
+	view.Append("1", "2\n3")
+	view.Append("4")
+	if lines := view.Lines(); len(lines) != 3 || lines[0] != "2" || lines[2] != "4" {
+		t.Errorf("lines: %v", lines)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view.Append("line")
+		}()
+	}
+	wg.Wait()
+	if view.Len() != 3 {
+		t.Errorf("len %d", view.Len())
+	}
+}
+
+func TestLogViewFollow(t *testing.T) {
+	view := LogView(10)
+	view.Append("a", "b", "c", "d")
+	canvas := NewStringCanvas(1, 2)
+	view.Render(canvas)
+	if got := canvas.String(); got != "c\nd\n" {
+		t.Errorf("tail: %q", got)
+	}
+	pressKey(view, term.KeyArrowUp, 0)
+	view.Append("e")
+	view.Render(canvas)
+	if got := canvas.String(); got != "b\nc\n" || view.Following() {
+		t.Errorf("paused: %q", got)
+	}
+	pressKey(view, term.KeyArrowDown, 0)
+	pressKey(view, term.KeyArrowDown, 0)
+	view.Append("f")
+	view.Render(canvas)
+	if got := canvas.String(); got != "e\nf\n" || !view.Following() {
+		t.Errorf("following again: %q", got)
+	}
+}
+
+func TestLogViewFilter(t *testing.T) {
+	view := LogView(10)
+	view.Append("INFO start", "ERROR failed", "INFO done")
+	view.SetFilter("INFO")
+	if lines := view.Lines(); len(lines) != 2 {
+		t.Errorf("substring filter: %v", lines)
+	}
+	view.SetFilterRegexp(regexp.MustCompile(`^ERROR`))
+	if lines := view.Lines(); len(lines) != 1 || lines[0] != "ERROR failed" {
+		t.Errorf("regexp filter: %v", lines)
+	}
+	if view.levelColor("ERROR failed") != uint16(term.ColorRed) {
+		t.Errorf("errors should be red")
+	}
+}
+
+func TestLogViewFilterPaused(t *testing.T) {
+	view := LogView(5)
+	view.Append("x", "E1", "E2", "E3", "E4")
+	view.SetFilter("E")
+	canvas := NewStringCanvas(2, 1)
+	view.Render(canvas)
+	pressKey(view, term.KeyArrowUp, 0)
+	pressKey(view, term.KeyArrowUp, 0)
+	view.Append("y")
+	view.Render(canvas)
+	if got := canvas.String(); got != "E2\n" {
+		t.Errorf("dropping a filtered out line should not move the view: %q", got)
+	}
+	view.Append("z")
+	view.Render(canvas)
+	if got := canvas.String(); got != "E2\n" {
+		t.Errorf("dropping a shown line above should keep the view: %q", got)
+	}
+}
+
+func TestLogViewRequestsRedraw(t *testing.T) {
+	s := NewScheduler()
+	view := LogView(10)
+	view.Render(WithScheduler(NewStringCanvas(1, 1), s))
+	view.Append("x")
+	if _, ok := s.Next(); !ok {
+		t.Error("appending should ask for a redraw")
+	}
+}
+
+func TestLogViewFocused(t *testing.T) {
+	view := LogView(10)
+	done := make(chan bool)
+	go func() {
+		view.Append("a", "b", "c", "d")
+		done <- true
+	}()
+	view.SetFocused(true)
+	<-done
+	canvas := NewStringCanvas(3, 2)
+	view.Render(canvas)
+	if got := canvas.String(); got != "c │\nd █\n" {
+		t.Errorf("focused: %q", got)
+	}
+}
@@ -0,0 +1,498 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown lays out a subset of markdown: atx headings,
+// paragraphs, bullet and numbered lists, block quotes,
+// fenced code, rules and inline emphasis, code and links.
+// The layer tree is rebuilt whenever the width
+// of the canvas changes, so paragraphs rewrap on resize.
+//
+// Its width and height are Free, but it is a HeightForWidth,
+// so in a Vlayer or a Scroll it takes the lines it needs.
+func Markdown(src string) Layer {
+	src = strings.Replace(src, "\t", "    ", -1)
+	return &markdownLayer{
+		blocks: parseBlocks(strings.Split(src, "\n")),
+		width:  -1,
+	}
+}
+
+var (
+	mdHeadingColor = uint16(term.ColorCyan) | uint16(term.AttrBold)
+	mdCodeColor    = uint16(term.ColorYellow)
+	mdLinkColor    = uint16(term.ColorBlue) | uint16(term.AttrUnderline)
+	mdQuoteColor   = uint16(term.ColorGreen)
+	mdStrong       = uint16(term.AttrBold)
+	mdEmphasis     = uint16(term.AttrUnderline)
+)
+
+type markdownLayer struct {
+	blocks []mdBlock
+	width  int
+	tree   Layer
+}
+
+func (md *markdownLayer) Width() size.T  { return size.Free }
+func (md *markdownLayer) Height() size.T { return size.Free }
+
+// HeightFor returns the number of lines
+// the document takes when laid out at width.
+func (md *markdownLayer) HeightFor(width int) (int, bool) {
+	_, h := computeDimension(md.layout(width), &nilCanvas{rect{width: width, height: 1 << 30}})
+	return h, true
+}
+
+func (md *markdownLayer) layout(width int) Layer {
+	if width != md.width || md.tree == nil {
+		md.width = width
+		md.tree = buildBlocks(md.blocks, width)
+	}
+	return md.tree
+}
+
+// Children has the layout of the last render, if any
+func (md *markdownLayer) Children() []Layer {
+	if md.tree == nil {
+		return nil
+	}
+	return []Layer{md.tree}
+}
+
+func (md *markdownLayer) Render(canvas Canvas) {
+	RenderChild(md.layout(canvas.Width()), canvas)
+}
+
+type mdKind int
+
+const (
+	mdParagraph mdKind = iota
+	mdHeading
+	mdList
+	mdQuote
+	mdCode
+	mdRule
+)
+
+type mdBlock struct {
+	kind    mdKind
+	level   int
+	text    string
+	lang    string
+	ordered bool
+	start   int
+	items   [][]mdBlock
+	blocks  []mdBlock
+}
+
+var (
+	mdHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	mdFenceRe   = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*(\\S*)")
+	mdQuoteRe   = regexp.MustCompile(`^ {0,3}> ?`)
+	mdRuleRe    = regexp.MustCompile(`^ {0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	mdItemRe    = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])( +|$)`)
+)
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// a line that ends a paragraph
+func startsBlock(line string) bool {
+	return mdHeadingRe.MatchString(line) || mdFenceRe.MatchString(line) ||
+		mdQuoteRe.MatchString(line) || mdRuleRe.MatchString(line) ||
+		mdItemRe.MatchString(line)
+}
+
+func parseBlocks(lines []string) []mdBlock {
+	var blocks []mdBlock
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			i++
+
+		case mdFenceRe.MatchString(line):
+			m := mdFenceRe.FindStringSubmatch(line)
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, mdBlock{
+				kind: mdCode,
+				lang: m[2],
+				text: strings.Join(code, "\n"),
+			})
+
+		case mdHeadingRe.MatchString(line):
+			m := mdHeadingRe.FindStringSubmatch(line)
+			blocks = append(blocks, mdBlock{kind: mdHeading, level: len(m[1]), text: m[2]})
+			i++
+
+		case mdRuleRe.MatchString(line):
+			blocks = append(blocks, mdBlock{kind: mdRule})
+			i++
+
+		case mdQuoteRe.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && mdQuoteRe.MatchString(lines[i]); i++ {
+				quoted = append(quoted, mdQuoteRe.ReplaceAllString(lines[i], ""))
+			}
+			blocks = append(blocks, mdBlock{kind: mdQuote, blocks: parseBlocks(quoted)})
+
+		case mdItemRe.MatchString(line):
+			var block mdBlock
+			block, i = parseList(lines, i)
+			blocks = append(blocks, block)
+
+		default:
+			para := []string{strings.TrimSpace(line)}
+			for i++; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+				para = append(para, strings.TrimSpace(lines[i]))
+			}
+			blocks = append(blocks, mdBlock{kind: mdParagraph, text: strings.Join(para, " ")})
+		}
+	}
+	return blocks
+}
+
+// Items continue with lines indented at least as far as the item text,
+// and with unindented lines until a blank line or another block.
+func parseList(lines []string, i int) (mdBlock, int) {
+	m := mdItemRe.FindStringSubmatch(lines[i])
+	marker := m[2]
+	block := mdBlock{kind: mdList, ordered: isDigit(marker[0])}
+	if block.ordered {
+		block.start, _ = strconv.Atoi(marker[:len(marker)-1])
+	}
+	// bullets must match, numbers must share the delimiter
+	delim := marker[len(marker)-1:]
+
+	for i < len(lines) {
+		m := mdItemRe.FindStringSubmatch(lines[i])
+		if m == nil || isDigit(m[2][0]) != block.ordered || !strings.HasSuffix(m[2], delim) {
+			break
+		}
+		indent := len(m[0])
+		if m[3] == "" || len(m[3]) > 4 {
+			indent = len(m[1]) + len(m[2]) + 1
+		}
+		item := []string{strings.TrimLeft(lines[i][len(m[0]):], " ")}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if isBlank(line) {
+				// a blank line only continues the item
+				// when the next line is indented into it
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) >= indent {
+					item = append(item, "")
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) >= indent {
+				item = append(item, line[indent:])
+			} else if !startsBlock(line) && !isBlank(item[len(item)-1]) {
+				item = append(item, strings.TrimSpace(line))
+			} else {
+				break
+			}
+		}
+		block.items = append(block.items, parseBlocks(item))
+		if i < len(lines) && isBlank(lines[i]) {
+			// a list survives blank lines between its items
+			j := i
+			for j < len(lines) && isBlank(lines[j]) {
+				j++
+			}
+			if j < len(lines) && mdItemRe.MatchString(lines[j]) {
+				i = j
+			}
+		}
+	}
+	return block, i
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isWordByte(ch byte) bool {
+	return isDigit(ch) || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// builds a tree of layers of constant size
+func buildBlocks(blocks []mdBlock, width int) Layer {
+	var layers []Layer
+	for i, block := range blocks {
+		if i > 0 {
+			layers = append(layers, SizeH(1, blank{}))
+		}
+		layers = append(layers, buildBlock(block, width))
+	}
+	return Vlayer(layers...)
+}
+
+func buildBlock(block mdBlock, width int) Layer {
+	switch block.kind {
+	case mdHeading:
+		text := wrapSpans(parseInline(block.text, mdHeadingColor), width)
+		switch block.level {
+		case 1:
+			return Vlayer(text, SizeH(1, lineUnder('═', text)))
+		case 2:
+			return Vlayer(text, SizeH(1, lineUnder('─', text)))
+		}
+		return text
+
+	case mdRule:
+		return Size(width, 1, CharBlock('─'))
+
+	case mdCode:
+		return CodeBlock(block.text, block.lang)
+
+	case mdQuote:
+		inner := buildBlocks(block.blocks, width-2)
+		return SetColor(mdQuoteColor, 0, Hlayer(
+			LineV('│'),
+			SizeW(1, blank{}),
+			inner,
+		))
+
+	case mdList:
+		markers := make([]string, len(block.items))
+		markerWidth := 0
+		for i := range block.items {
+			if block.ordered {
+				markers[i] = strconv.Itoa(block.start+i) + ". "
+			} else {
+				markers[i] = "• "
+			}
+			if n := len([]rune(markers[i])); n > markerWidth {
+				markerWidth = n
+			}
+		}
+		var items []Layer
+		for i, item := range block.items {
+			items = append(items, Hlayer(
+				Size(markerWidth, 1, AlignRight(TextLine(markers[i]))),
+				buildItem(item, width-markerWidth),
+			))
+		}
+		return Vlayer(items...)
+	}
+	return wrapSpans(parseInline(block.text, 0), width)
+}
+
+// list items are tight, their paragraphs are not spaced apart
+func buildItem(blocks []mdBlock, width int) Layer {
+	var layers []Layer
+	for i, block := range blocks {
+		if i > 0 && block.kind != mdList {
+			layers = append(layers, SizeH(1, blank{}))
+		}
+		layers = append(layers, buildBlock(block, width))
+	}
+	return Vlayer(layers...)
+}
+
+func lineUnder(ch rune, ref Layer) Layer {
+	return SyncSizeW(ref, CharBlock(ch))
+}
+
+type span struct {
+	text string
+	fg   uint16
+}
+
+// emphasis markers, longest first
+var mdInlineMarks = []struct {
+	mark string
+	attr uint16
+}{
+	{"**", mdStrong},
+	{"__", mdStrong},
+	{"*", mdEmphasis},
+	{"_", mdEmphasis},
+}
+
+var mdLinkRe = regexp.MustCompile(`^\[([^\]]*)\]\(([^)\s]*)\)`)
+
+// mdColorMask has the colour bits of a style, below the attributes
+const mdColorMask = uint16(term.AttrBold) - 1
+
+// mdStyle is the style of text with style inner inside text with
+// style outer: the colour of inner, if it has one, or else that of
+// outer, with the attributes of both
+func mdStyle(outer, inner uint16) uint16 {
+	color := outer & mdColorMask
+	if inner&mdColorMask != 0 {
+		color = inner & mdColorMask
+	}
+	return color | (outer|inner)&^mdColorMask
+}
+
+func parseInline(s string, fg uint16) []span {
+	var spans []span
+	text := ""
+	flush := func() {
+		if text != "" {
+			spans = append(spans, span{text, fg})
+			text = ""
+		}
+	}
+outer:
+	for i := 0; i < len(s); {
+		if s[i] == '\\' && i+1 < len(s) {
+			text += s[i+1 : i+2]
+			i += 2
+			continue
+		}
+		if s[i] == '`' {
+			if j := strings.IndexByte(s[i+1:], '`'); j >= 0 {
+				flush()
+				spans = append(spans, span{s[i+1 : i+1+j], mdStyle(fg, mdCodeColor)})
+				i += j + 2
+				continue
+			}
+		}
+		if m := mdLinkRe.FindStringSubmatch(s[i:]); m != nil {
+			flush()
+			spans = append(spans, parseInline(m[1], mdStyle(fg, mdLinkColor))...)
+			if m[2] != "" && m[2] != m[1] {
+				spans = append(spans, span{" (" + m[2] + ")", fg})
+			}
+			i += len(m[0])
+			continue
+		}
+		for _, em := range mdInlineMarks {
+			if !strings.HasPrefix(s[i:], em.mark) {
+				continue
+			}
+			// snake_case is not emphasis
+			if em.mark[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+				continue
+			}
+			rest := s[i+len(em.mark):]
+			j := strings.Index(rest, em.mark)
+			// a marker must hug the text it emphasizes
+			if j > 0 && rest[0] != ' ' && rest[j-1] != ' ' {
+				flush()
+				spans = append(spans, parseInline(rest[:j], mdStyle(fg, em.attr))...)
+				i += len(em.mark)*2 + j
+				continue outer
+			}
+		}
+		text += s[i : i+1]
+		i++
+	}
+	flush()
+	return spans
+}
+
+type richText struct {
+	lines [][]span
+	width int
+}
+
+func (text *richText) Width() size.T  { return size.Const(text.width) }
+func (text *richText) Height() size.T { return size.Const(len(text.lines)) }
+
+func (text *richText) Render(canvas Canvas) {
+	for y, line := range text.lines {
+		x := 0
+		for _, sp := range line {
+			for _, ch := range sp.text {
+				canvas.Draw(x, y, ch, sp.fg, 0)
+				x++
+			}
+		}
+	}
+}
+
+// wrapSpans breaks the spans into lines no wider than width,
+// at spaces where possible. Runs of spaces collapse into one.
+func wrapSpans(spans []span, width int) *richText {
+	if width < 1 {
+		width = 1
+	}
+	type word struct {
+		parts []span
+		n     int
+	}
+	// words may cross span boundaries, as in **wind**'s
+	var words []word
+	var cur word
+	gap := false
+	for _, sp := range spans {
+		for _, field := range strings.SplitAfter(sp.text, " ") {
+			trimmed := strings.TrimRight(field, " ")
+			if trimmed != "" {
+				if gap && cur.n > 0 {
+					words = append(words, cur)
+					cur = word{}
+				}
+				cur.parts = append(cur.parts, span{trimmed, sp.fg})
+				cur.n += len([]rune(trimmed))
+				gap = false
+			}
+			if trimmed != field {
+				gap = true
+			}
+		}
+	}
+	if cur.n > 0 {
+		words = append(words, cur)
+	}
+
+	text := &richText{}
+	var line []span
+	n := 0
+	newline := func() {
+		text.lines = append(text.lines, line)
+		if n > text.width {
+			text.width = n
+		}
+		line, n = nil, 0
+	}
+	for _, w := range words {
+		if n > 0 && n+1+w.n > width {
+			newline()
+		}
+		if n > 0 {
+			line = append(line, span{" ", 0})
+			n++
+		}
+		for _, part := range w.parts {
+			runes := []rune(part.text)
+			// break words that do not fit on a line of their own
+			for n+len(runes) > width {
+				k := width - n
+				line = append(line, span{string(runes[:k]), part.fg})
+				n += k
+				newline()
+				runes = runes[k:]
+			}
+			line = append(line, span{string(runes), part.fg})
+			n += len(runes)
+		}
+	}
+	if n > 0 || len(text.lines) == 0 {
+		newline()
+	}
+	return text
+}
@@ -0,0 +1,109 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"strings"
+	"testing"
+)
+
+const testMarkdown = `# Help
+
+Press **q** to quit, or *anything* else to ` + "`continue`" + `.
+See [wind](https://github.com/nvlled/wind).
+
+- one
+- two
+  - nested
+1. first
+2. second
+
+> quoted text
+> and more
+
+` + "```go\nfunc main() {}\n```" + `
+---
+`
+
+func TestMarkdownBlocks(t *testing.T) {
+	blocks := parseBlocks(strings.Split(testMarkdown, "\n"))
+	kinds := []mdKind{mdHeading, mdParagraph, mdList, mdList, mdQuote, mdCode, mdRule}
+	if len(blocks) != len(kinds) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(kinds))
+	}
+	for i, block := range blocks {
+		if block.kind != kinds[i] {
+			t.Errorf("block %d is %v, want %v", i, block.kind, kinds[i])
+		}
+	}
+	if items := blocks[2].items; len(items) != 2 || len(items[1]) != 2 {
+		t.Errorf("unexpected list items: %v", items)
+	}
+	if blocks[3].start != 1 || !blocks[3].ordered {
+		t.Errorf("expected an ordered list: %v", blocks[3])
+	}
+	if blocks[5].lang != "go" || blocks[5].text != "func main() {}" {
+		t.Errorf("unexpected code block: %v", blocks[5])
+	}
+}
+
+func TestMarkdownWrap(t *testing.T) {
+	spans := parseInline("a **bold** move_along _it_", 0)
+	if len(spans) != 4 || spans[1].text != "bold" || spans[1].fg != mdStrong ||
+		spans[2].text != " move_along " || spans[3].fg != mdEmphasis {
+		t.Errorf("unexpected spans: %v", spans)
+	}
+	link := parseInline("[wind](url)", mdHeadingColor)
+	if want := uint16(term.ColorBlue) | uint16(term.AttrUnderline) | uint16(term.AttrBold); link[0].fg != want {
+		t.Errorf("a link in a heading: %x, want %x", link[0].fg, want)
+	}
+	text := wrapSpans(parseInline("aaa bbb ccc dddddddd", 0), 7)
+	if len(text.lines) != 4 || text.width != 7 {
+		t.Errorf("unexpected wrap: %v", text.lines)
+	}
+
+	layer := Markdown(testMarkdown).(*markdownLayer)
+	narrow, _ := layer.HeightFor(20)
+	wide, _ := layer.HeightFor(80)
+	if narrow <= wide {
+		t.Errorf("narrow layout should be taller: %d <= %d", narrow, wide)
+	}
+	canvas := NewStringCanvas(40, 19)
+	layer.Render(canvas)
+	want := "Help                                    \n" +
+		"════                                    \n" +
+		"                                        \n" +
+		"Press q to quit, or anything else to    \n" +
+		"continue. See wind                      \n" +
+		"(https://github.com/nvlled/wind).       \n" +
+		"                                        \n" +
+		"• one                                   \n" +
+		"• two                                   \n" +
+		"  • nested                              \n" +
+		"                                        \n" +
+		"1. first                                \n" +
+		"2. second                               \n" +
+		"                                        \n" +
+		"│ quoted text and more                  \n" +
+		"                                        \n" +
+		"func main() {}                          \n" +
+		"                                        \n" +
+		"────────────────────────────────────────\n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
+
+func TestMarkdownInVlayer(t *testing.T) {
+	layer := Vlayer(Markdown("one two three four"), TextLine("end"))
+	canvas := NewStringCanvas(5, 6)
+	layer.Render(canvas)
+	want := "one  \n" +
+		"two  \n" +
+		"three\n" +
+		"four \n" +
+		"end  \n" +
+		"     \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+}
@@ -0,0 +1,307 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"strings"
+	"unicode/utf8"
+)
+
+// OverlayLayer draws popups over a layer. The popups stack, the
+// last one shown is on top. While there are popups, the focus
+// stays inside the top one, clicks outside of it are ignored,
+// and Esc cancels it. The keys the popup does not use are
+// dropped, so they do not reach the keymap of the App.
+//
+// Put the OverlayLayer at the root, so the popups can cover
+// everything and be placed by any layer on the screen.
+type OverlayLayer struct {
+	layer  Layer
+	popups []*Popup
+
+	// the layer to focus again after the top popup closed
+	restore Focusable
+}
+
+func Overlay(layer Layer) *OverlayLayer {
+	return &OverlayLayer{layer: wrapNil(layer)}
+}
+
+type Popup struct {
+	overlay  *OverlayLayer
+	layer    Layer
+	anchor   Layer
+	dim      bool
+	onCancel func()
+
+	// the layer that had the focus before the popup was opened
+	restore Focusable
+	saved   bool
+}
+
+func (o *OverlayLayer) Layer() Layer { return o.layer }
+
+// Popups returns the shown popups, the top one last.
+func (o *OverlayLayer) Popups() []*Popup {
+	return append([]*Popup{}, o.popups...)
+}
+
+func (o *OverlayLayer) Top() *Popup {
+	if len(o.popups) == 0 {
+		return nil
+	}
+	return o.popups[len(o.popups)-1]
+}
+
+// Show puts layer centred over the others
+// at the size it asks for.
+func (o *OverlayLayer) Show(layer Layer) *Popup {
+	p := &Popup{overlay: o, layer: wrapNil(layer)}
+	o.popups = append(o.popups, p)
+	return p
+}
+
+func (p *Popup) Layer() Layer { return p.layer }
+
+// AnchorTo places the popup under the rectangle where anchor was
+// drawn, or over it when there is no room below, like a dropdown.
+// This needs a HitMap, as App uses, to know where anchor is.
+// Without one, or while anchor is not shown, the popup is centred.
+func (p *Popup) AnchorTo(anchor Layer) *Popup {
+	p.anchor = anchor
+	return p
+}
+
+// Dim draws everything under the popup dimmed.
+func (p *Popup) Dim(dim bool) *Popup {
+	p.dim = dim
+	return p
+}
+
+// OnCancel sets the handler for when the popup is closed with Esc.
+func (p *Popup) OnCancel(handler func()) *Popup {
+	p.onCancel = handler
+	return p
+}
+
+// Close removes the popup. When it is the top one, a Focus gives
+// the focus back to the layer that had it before the popup opened.
+func (p *Popup) Close() {
+	o := p.overlay
+	for i, other := range o.popups {
+		if other == p {
+			if i == len(o.popups)-1 {
+				o.restore = p.restore
+			}
+			o.popups = append(o.popups[:i], o.popups[i+1:]...)
+			return
+		}
+	}
+}
+
+// Cancel closes the popup and runs the OnCancel handler.
+func (p *Popup) Cancel() {
+	p.Close()
+	if p.onCancel != nil {
+		p.onCancel()
+	}
+}
+
+func (o *OverlayLayer) Width() size.T  { return o.layer.Width() }
+func (o *OverlayLayer) Height() size.T { return o.layer.Height() }
+
+// Children returns the layer under the popups, then the popups.
+func (o *OverlayLayer) Children() []Layer {
+	layers := []Layer{o.layer}
+	for _, popup := range o.popups {
+		layers = append(layers, popup.layer)
+	}
+	return layers
+}
+
+// Shown returns only the top popup, to keep the focus in it,
+// or the layer under the popups when none is open.
+func (o *OverlayLayer) Shown() []Layer {
+	if top := o.Top(); top != nil {
+		return []Layer{top.layer}
+	}
+	return []Layer{o.layer}
+}
+
+func (o *OverlayLayer) Render(canvas Canvas) {
+	// everything under the top dimming popup is dimmed
+	dimmed := -1
+	for i, p := range o.popups {
+		if p.dim {
+			dimmed = i
+		}
+	}
+	below := canvas
+	if dimmed >= 0 {
+		below = &dimCanvas{canvas}
+	}
+
+	RenderChild(o.layer, below)
+	for i, p := range o.popups {
+		c := canvas
+		if i < dimmed {
+			c = below
+		}
+		RenderChild(backdrop{}, c)
+		x, y, w, h := o.place(p, canvas)
+		RenderChild(p.layer, c.New(x, y, w, h))
+	}
+}
+
+func (o *OverlayLayer) place(p *Popup, canvas Canvas) (x, y, w, h int) {
+	cw, ch := canvas.Dimension()
+	w, h = computeDimension(p.layer, canvas)
+	x, y = (cw-w)/2, (ch-h)/2
+
+	hits := hitMapOf(canvas)
+	if p.anchor == nil || hits == nil {
+		return x, y, w, h
+	}
+	ax, ay, _, ah, ok := hits.Rect(p.anchor)
+	if !ok {
+		return x, y, w, h
+	}
+	baseX, baseY := canvas.Base()
+	x, y = ax-baseX, ay-baseY+ah
+	if y+h > ch && ay-baseY-h >= 0 {
+		y = ay - baseY - h
+	}
+	return clamp(x, 0, max(0, cw-w)), clamp(y, 0, max(0, ch-h)), w, h
+}
+
+func (o *OverlayLayer) saveFocus(focused Focusable) {
+	for _, p := range o.popups {
+		if !p.saved {
+			p.restore, p.saved = focused, true
+		}
+	}
+}
+
+func (o *OverlayLayer) restoredFocus() Focusable {
+	restore := o.restore
+	o.restore = nil
+	return restore
+}
+
+func (o *OverlayLayer) capturesKeys() bool { return o.Top() != nil }
+
+func (o *OverlayLayer) HandleKey(e term.Event) bool {
+	if top := o.Top(); top != nil && e.Key == term.KeyEsc && e.Mod == 0 {
+		top.Cancel()
+		return true
+	}
+	return false
+}
+
+// backdrop is under every popup, it takes
+// the clicks that miss the popup
+type backdrop struct{}
+
+func (backdrop) Width() size.T                           { return size.Free }
+func (backdrop) Height() size.T                          { return size.Free }
+func (backdrop) Render(canvas Canvas)                    {}
+func (backdrop) HandleMouse(e term.Event, x, y int) bool { return true }
+
+// dimCanvas draws everything with the dim attribute.
+type dimCanvas struct {
+	Canvas
+}
+
+func (canvas *dimCanvas) unwrap() Canvas { return canvas.Canvas }
+
+func (canvas *dimCanvas) New(x, y, width, height int) Canvas {
+	return &dimCanvas{canvas.Canvas.New(x, y, width, height)}
+}
+
+func (canvas *dimCanvas) Draw(x, y int, ch rune, fg, bg uint16) {
+	canvas.Canvas.Draw(x, y, ch, fg|uint16(term.AttrDim), bg)
+}
+
+func (canvas *dimCanvas) DrawText(x, y int, s string, fg, bg uint16) {
+	canvas.Canvas.DrawText(x, y, s, fg|uint16(term.AttrDim), bg)
+}
+
+// dialog puts body and a row of buttons in a border
+func dialog(body Layer, buttons ...Layer) Layer {
+	var row []Layer
+	for i, button := range buttons {
+		if i > 0 {
+			row = append(row, Text(" "))
+		}
+		row = append(row, button)
+	}
+	return Frame(SingleBorder, Vlayer(body, Text(""), Hlayer(row...)))
+}
+
+func message(s string) Layer {
+	return Text(strings.TrimRight(s, "\n"))
+}
+
+// Alert shows message with an OK button. done is called when the
+// alert is closed, with the button or with Esc. done may be nil.
+func (o *OverlayLayer) Alert(msg string, done func()) *Popup {
+	var p *Popup
+	ok := Button("OK", func() {
+		p.Close()
+		if done != nil {
+			done()
+		}
+	})
+	p = o.Show(dialog(message(msg), ok)).Dim(true).OnCancel(done)
+	return p
+}
+
+// Confirm asks a yes or no question. The answer is given
+// with the buttons, the y and n keys, or Esc for no.
+func (o *OverlayLayer) Confirm(msg string, answer func(yes bool)) *Popup {
+	var p *Popup
+	reply := func(yes bool) {
+		p.Close()
+		answer(yes)
+	}
+	yes := Button("Yes", func() { reply(true) })
+	no := Button("No", func() { reply(false) })
+	body := OnKey(dialog(message(msg), yes, no), func(e term.Event) bool {
+		switch e.Ch {
+		case 'y', 'Y':
+			reply(true)
+		case 'n', 'N':
+			reply(false)
+		default:
+			return false
+		}
+		return true
+	})
+	p = o.Show(body).Dim(true).OnCancel(func() { answer(false) })
+	return p
+}
+
+// Prompt asks for a line of text, starting with text. The answer
+// is given with Enter or the OK button, or Esc or Cancel to give
+// ok as false.
+func (o *OverlayLayer) Prompt(msg, text string, answer func(text string, ok bool)) *Popup {
+	var p *Popup
+	input := Input().SetText(text)
+	reply := func(ok bool) {
+		p.Close()
+		answer(input.Text(), ok)
+	}
+	input.OnSubmit(func(string) { reply(true) })
+
+	body := message(msg)
+	w := 30
+	for _, line := range strings.Split(msg, "\n") {
+		w = max(w, utf8.RuneCountInString(line))
+	}
+	field := Size(w, 1, input)
+	p = o.Show(dialog(Vlayer(body, field),
+		Button("OK", func() { reply(true) }),
+		Button("Cancel", func() { reply(false) }),
+	)).Dim(true).OnCancel(func() { answer(input.Text(), false) })
+	return p
+}
@@ -0,0 +1,122 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestPopupConfirm(t *testing.T) {
+	background := Button("background", nil)
+	overlay := Overlay(Vlayer(Button("first", nil), background))
+	focus := NewFocus(overlay)
+	focus.SetFocus(background)
+
+	var answers []bool
+	overlay.Confirm("Quit?", func(yes bool) { answers = append(answers, yes) })
+	canvas := NewStringCanvas(16, 7)
+	overlay.Render(canvas)
+	want := "[ first ]       \n" +
+		"┌──────────────┐\n" +
+		"│Quit?         │\n" +
+		"│              │\n" +
+		"│[ Yes ] [ No ]│\n" +
+		"└──────────────┘\n" +
+		"                \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+
+	focus.Refresh()
+	if button, ok := focus.Focused().(*ButtonLayer); !ok || button.Label() != "Yes" {
+		t.Fatalf("focus should move into the popup")
+	}
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyTab})
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyTab})
+	if focus.Focused().(*ButtonLayer).Label() != "Yes" {
+		t.Errorf("focus should not leave the popup")
+	}
+	focus.HandleEvent(term.Event{Type: term.EventKey, Ch: 'n'})
+	focus.Refresh()
+	if len(answers) != 1 || answers[0] || overlay.Top() != nil {
+		t.Errorf("answers %v", answers)
+	}
+	if focus.Focused() != background {
+		t.Errorf("focus should go back after closing")
+	}
+
+	overlay.Confirm("Again?", func(yes bool) { answers = append(answers, yes) })
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyEsc})
+	if deadline, ok := focus.Deadline(); !ok || !focus.Expire(deadline) {
+		t.Errorf("Esc should be used at the deadline")
+	}
+	if len(answers) != 2 || answers[1] || overlay.Top() != nil {
+		t.Errorf("Esc should answer no: %v", answers)
+	}
+}
+
+func TestPopupStackAndPrompt(t *testing.T) {
+	overlay := Overlay(nil)
+	focus := NewFocus(overlay)
+	var result string
+	overlay.Alert("first", nil)
+	overlay.Prompt("Name:", "wi", func(text string, ok bool) {
+		if ok {
+			result = text
+		}
+	})
+	if len(overlay.Popups()) != 2 {
+		t.Fatalf("expected two popups")
+	}
+	focus.Refresh()
+	typeKeys(focus.Focused(), "nd")
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyEnter})
+	if result != "wind" {
+		t.Errorf("prompt answered %q", result)
+	}
+	focus.Refresh()
+	if button, ok := focus.Focused().(*ButtonLayer); !ok || button.Label() != "OK" {
+		t.Errorf("the alert should have the focus")
+	}
+}
+
+func TestPopupAnchor(t *testing.T) {
+	anchor := Size(3, 1, RenderLayer(func(Canvas) {}))
+	overlay := Overlay(Vlayer(Text("   "), Hlayer(Text("  "), anchor)))
+	popup := Text("pop")
+	overlay.Show(popup).AnchorTo(anchor)
+	hits := NewHitMap()
+	hits.Render(overlay, NewStringCanvas(6, 4))
+	if x, y, _, _, _ := hits.Rect(popup); x != 2 || y != 2 {
+		t.Errorf("popup at %d, %d", x, y)
+	}
+	path := hits.HitTest(0, 0)
+	if _, ok := path[len(path)-1].(backdrop); !ok {
+		t.Errorf("clicks outside the popup should hit the backdrop")
+	}
+}
+
+func TestPopupCapturesKeys(t *testing.T) {
+	overlay := Overlay(Button("background", nil))
+	app := NewApp(overlay)
+	ran := false
+	app.SetKeymap(NewKeymap().MustBind("x", "global", func() { ran = true }))
+	var answers []bool
+	overlay.Confirm("Quit?", func(yes bool) { answers = append(answers, yes) })
+	app.focus.Refresh()
+
+	app.handleKey(term.Event{Type: term.EventKey, Ch: 'q'})
+	app.handleKey(term.Event{Type: term.EventKey, Ch: 'x'})
+	select {
+	case <-app.quit:
+		t.Fatalf("q in a popup should not quit")
+	default:
+	}
+	if ran || len(answers) != 0 || overlay.Top() == nil {
+		t.Errorf("keys should stay in the popup: ran %v, answers %v", ran, answers)
+	}
+	app.handleKey(term.Event{Type: term.EventKey, Key: term.KeyTab})
+	if button, ok := app.focus.Focused().(*ButtonLayer); !ok || button.Label() != "No" {
+		t.Errorf("tab should still move the focus in the popup")
+	}
+	app.Quit()
+}
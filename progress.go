@@ -0,0 +1,152 @@
+package wind
+
+import (
+	"fmt"
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"unicode/utf8"
+)
+
+// ProgressLayer fills a bar in proportion to a value. The end of
+// the bar is drawn with the eighth block characters, so it moves
+// in steps of an eighth of a cell, or in whole cells with '#'
+// and '-' for terminals without those characters.
+type ProgressLayer struct {
+	value, max float64
+	vertical   bool
+	thick      bool
+	label      bool
+	ascii      bool
+
+	fillFg, fillBg   uint16
+	emptyFg, emptyBg uint16
+}
+
+// ProgressBar is a one line bar, as wide as it gets,
+// filled to fraction, from 0 to 1.
+func ProgressBar(fraction float64) *ProgressLayer {
+	return &ProgressLayer{value: fraction, max: 1}
+}
+
+// Gauge is a bar that fills all the space it gets and shows
+// value as a percentage of max in the middle.
+func Gauge(value, max float64) *ProgressLayer {
+	return &ProgressLayer{value: value, max: max, thick: true, label: true}
+}
+
+// Fraction is the filled part, between 0 and 1.
+func (p *ProgressLayer) Fraction() float64 {
+	if p.max <= 0 || !(p.value > 0) {
+		return 0
+	}
+	return min(p.value/p.max, 1)
+}
+
+func (p *ProgressLayer) SetFraction(fraction float64) *ProgressLayer {
+	return p.SetValue(fraction, 1)
+}
+
+func (p *ProgressLayer) SetValue(value, max float64) *ProgressLayer {
+	p.value, p.max = value, max
+	return p
+}
+
+// SetVertical makes the bar fill from the bottom up.
+// A vertical ProgressBar is one column wide.
+func (p *ProgressLayer) SetVertical(vertical bool) *ProgressLayer {
+	p.vertical = vertical
+	return p
+}
+
+// ShowLabel shows the percentage in the middle of the bar.
+func (p *ProgressLayer) ShowLabel(show bool) *ProgressLayer {
+	p.label = show
+	return p
+}
+
+func (p *ProgressLayer) SetASCII(ascii bool) *ProgressLayer {
+	p.ascii = ascii
+	return p
+}
+
+func (p *ProgressLayer) SetFillStyle(fg, bg uint16) *ProgressLayer {
+	p.fillFg, p.fillBg = fg, bg
+	return p
+}
+
+func (p *ProgressLayer) SetEmptyStyle(fg, bg uint16) *ProgressLayer {
+	p.emptyFg, p.emptyBg = fg, bg
+	return p
+}
+
+func (p *ProgressLayer) Width() size.T {
+	if p.vertical && !p.thick {
+		return size.Const(1)
+	}
+	return size.Free
+}
+
+func (p *ProgressLayer) Height() size.T {
+	if !p.vertical && !p.thick {
+		return size.Const(1)
+	}
+	return size.Free
+}
+
+var (
+	leftEighths  = []rune(" ▏▎▍▌▋▊▉█")
+	lowerEighths = []rune(" ▁▂▃▄▅▆▇█")
+)
+
+// filled returns how many eighths of the cell at x, y are filled
+func (p *ProgressLayer) filled(x, y, w, h int) int {
+	length, i := w, x
+	if p.vertical {
+		length, i = h, h-1-y
+	}
+	eighths := int(p.Fraction()*float64(length*8) + 0.5)
+	return clamp(eighths-i*8, 0, 8)
+}
+
+func (p *ProgressLayer) cell(eighths int) (rune, uint16, uint16) {
+	switch {
+	case p.ascii && eighths >= 4:
+		return '#', p.fillFg, p.fillBg
+	case p.ascii:
+		return '-', p.emptyFg, p.emptyBg
+	case eighths == 8:
+		return '█', p.fillFg, p.fillBg
+	case eighths == 0:
+		return ' ', p.emptyFg, p.emptyBg
+	case p.vertical:
+		return lowerEighths[eighths], p.fillFg, p.emptyBg
+	}
+	return leftEighths[eighths], p.fillFg, p.emptyBg
+}
+
+func (p *ProgressLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ch, fg, bg := p.cell(p.filled(x, y, w, h))
+			canvas.Draw(x, y, ch, fg, bg)
+		}
+	}
+	if !p.label || h <= 0 {
+		return
+	}
+
+	// the label is reversed where it is over the filled part
+	label := fmt.Sprintf("%d%%", int(p.Fraction()*100))
+	x, y := (w-utf8.RuneCountInString(label))/2, h/2
+	for _, ch := range label {
+		if x >= 0 && x < w {
+			fg, bg := p.emptyFg, p.emptyBg
+			if p.filled(x, y, w, h) >= 4 {
+				fg, bg = p.fillFg|uint16(term.AttrReverse), p.fillBg
+			}
+			canvas.Draw(x, y, ch, fg, bg)
+		}
+		x++
+	}
+}
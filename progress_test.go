@@ -0,0 +1,37 @@
+package wind
+
+import "testing"
+
+func TestProgressBar(t *testing.T) {
+	bar := ProgressBar(0.3)
+	canvas := NewStringCanvas(5, 1)
+	bar.Render(canvas)
+	if got := canvas.String(); got != "█▌   \n" {
+		t.Errorf("30%%: %q", got)
+	}
+	bar.SetASCII(true).Render(canvas)
+	if got := canvas.String(); got != "##---\n" {
+		t.Errorf("ascii: %q", got)
+	}
+	bar.SetValue(12, 10).Render(canvas)
+	if got := canvas.String(); got != "#####\n" || bar.Fraction() != 1 {
+		t.Errorf("over max: %q", got)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	gauge := Gauge(1, 4)
+	canvas := NewStringCanvas(6, 3)
+	gauge.Render(canvas)
+	if got := canvas.String(); got != "█▌    \n█25%  \n█▌    \n" {
+		t.Errorf("horizontal: %q", got)
+	}
+	gauge.SetVertical(true).ShowLabel(false).Render(canvas)
+	if got := canvas.String(); got != "      \n      \n▆▆▆▆▆▆\n" {
+		t.Errorf("vertical: %q", got)
+	}
+	bar := ProgressBar(0.5).SetVertical(true)
+	if w, h := computeDimension(bar, canvas); w != 1 || h != 3 {
+		t.Errorf("vertical bar size %dx%d", w, h)
+	}
+}
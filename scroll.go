@@ -0,0 +1,267 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+)
+
+// ScrollLayer shows a window into a layer that is larger than
+// the space it gets. The layer is laid out at its preferred size
+// on a virtual canvas, and only the part at the scroll offset is
+// drawn. Free sizes take the size of the window, so a Vlayer of
+// text lines scrolls vertically only. Markdown, which is Free but
+// knows how tall it is at a given width, scrolls at its full height.
+//
+// The arrow keys, PgUp, PgDn, Home and End scroll when the focused
+// layer inside does not take them, and so does the mouse wheel.
+// When there is no focusable layer inside, the scroll takes the
+// focus itself, and shows the vertical scrollbar while it has it.
+type ScrollLayer struct {
+	layer Layer
+	x, y  int
+
+	vbar, hbar bool
+	focused    bool
+
+	// from the last render
+	viewW, viewH       int
+	contentW, contentH int
+}
+
+func Scroll(layer Layer) *ScrollLayer {
+	return &ScrollLayer{layer: wrapNil(layer)}
+}
+
+func (s *ScrollLayer) Layer() Layer { return s.layer }
+
+// Offset is the position in the layer shown at the top left.
+func (s *ScrollLayer) Offset() (int, int) { return s.x, s.y }
+
+// ContentSize is the size the layer had in the last render.
+func (s *ScrollLayer) ContentSize() (int, int) { return s.contentW, s.contentH }
+
+// ViewSize is the size of the window in the last render,
+// without the scrollbars.
+func (s *ScrollLayer) ViewSize() (int, int) { return s.viewW, s.viewH }
+
+// SetScrollbars reserves the rightmost column for a vertical
+// scrollbar and the bottom line for a horizontal one.
+func (s *ScrollLayer) SetScrollbars(vertical, horizontal bool) *ScrollLayer {
+	s.vbar = vertical
+	s.hbar = horizontal
+	return s
+}
+
+// ScrollTo moves the offset, clamped to the content on render.
+func (s *ScrollLayer) ScrollTo(x, y int) *ScrollLayer {
+	s.x, s.y = x, y
+	s.clampOffset()
+	return s
+}
+
+func (s *ScrollLayer) ScrollBy(dx, dy int) *ScrollLayer {
+	return s.ScrollTo(s.x+dx, s.y+dy)
+}
+
+// EnsureVisible scrolls as little as possible to show the
+// rectangle at x, y of the layer. If it does not fit,
+// its top left corner is shown.
+func (s *ScrollLayer) EnsureVisible(x, y, w, h int) *ScrollLayer {
+	return s.ScrollTo(
+		scrollInto(s.x, s.viewW, x, w),
+		scrollInto(s.y, s.viewH, y, h),
+	)
+}
+
+// scrollInto returns the offset nearest to offset
+// that shows pos to pos+length in a view
+func scrollInto(offset, view, pos, length int) int {
+	if pos+length > offset+view {
+		offset = pos + length - view
+	}
+	if pos < offset {
+		offset = pos
+	}
+	return offset
+}
+
+func (s *ScrollLayer) clampOffset() {
+	// before the first render the sizes are not known yet
+	if s.viewW == 0 && s.viewH == 0 {
+		return
+	}
+	s.x = clamp(s.x, 0, max(0, s.contentW-s.viewW))
+	s.y = clamp(s.y, 0, max(0, s.contentH-s.viewH))
+}
+
+func (s *ScrollLayer) Width() size.T  { return size.Free }
+func (s *ScrollLayer) Height() size.T { return size.Free }
+
+// contentSize is the preferred size of the layer,
+// but no smaller than the view
+func (s *ScrollLayer) contentSize(viewW, viewH int) (int, int) {
+	w := preferredSize(s.layer.Width(), viewW)
+	if h, ok := heightFor(s.layer, w); ok {
+		return w, max(viewH, h)
+	}
+	return w, preferredSize(s.layer.Height(), viewH)
+}
+
+func preferredSize(s size.T, view int) int {
+	switch s.(type) {
+	case size.FreeT, size.AdaptT:
+		return view
+	}
+	return max(view, s.Value(1<<30))
+}
+
+func (s *ScrollLayer) Children() []Layer { return []Layer{s.layer} }
+
+func (s *ScrollLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	s.viewW, s.viewH = w, h
+	vbar := s.vbar || s.focused
+	if vbar {
+		s.viewW = max(0, w-1)
+	}
+	if s.hbar {
+		s.viewH = max(0, h-1)
+	}
+	s.contentW, s.contentH = s.contentSize(s.viewW, s.viewH)
+	s.clampOffset()
+
+	view := canvas.New(0, 0, s.viewW, s.viewH)
+	RenderChild(s.layer, &scrollCanvas{
+		view:   view,
+		rect:   rect{width: s.contentW, height: s.contentH},
+		window: rect{s.x, s.y, s.viewW, s.viewH},
+	})
+
+	if vbar {
+		drawScrollbar(canvas.New(s.viewW, 0, 1, s.viewH), true, s.y, s.viewH, s.contentH)
+	}
+	if s.hbar {
+		drawScrollbar(canvas.New(0, s.viewH, s.viewW, 1), false, s.x, s.viewW, s.contentW)
+	}
+}
+
+func drawScrollbar(canvas Canvas, vertical bool, offset, view, content int) {
+	length, track := view, '│'
+	if !vertical {
+		track = '─'
+	}
+	thumb, start := length, 0
+	if content > view {
+		thumb = max(1, length*view/content)
+		start = offset * (length - thumb) / (content - view)
+	}
+	for i := 0; i < length; i++ {
+		ch := track
+		if i >= start && i < start+thumb {
+			ch = '█'
+		}
+		if vertical {
+			canvas.Draw(0, i, ch, 0, 0)
+		} else {
+			canvas.Draw(i, 0, ch, 0, 0)
+		}
+	}
+}
+
+func (s *ScrollLayer) SetFocused(focused bool) { s.focused = focused }
+
+func (s *ScrollLayer) focusFallback() {}
+
+func (s *ScrollLayer) HandleKey(e term.Event) bool {
+	if e.Ch != 0 || e.Mod != 0 {
+		return false
+	}
+	page := max(1, s.viewH-1)
+	switch e.Key {
+	case term.KeyArrowUp:
+		s.ScrollBy(0, -1)
+	case term.KeyArrowDown:
+		s.ScrollBy(0, 1)
+	case term.KeyArrowLeft:
+		s.ScrollBy(-1, 0)
+	case term.KeyArrowRight:
+		s.ScrollBy(1, 0)
+	case term.KeyPgup:
+		s.ScrollBy(0, -page)
+	case term.KeyPgdn:
+		s.ScrollBy(0, page)
+	case term.KeyHome:
+		s.ScrollTo(s.x, 0)
+	case term.KeyEnd:
+		s.ScrollTo(s.x, s.contentH)
+	default:
+		return false
+	}
+	return true
+}
+
+// clicking on a scrollbar jumps to that part of the content
+func (s *ScrollLayer) HandleMouse(e term.Event, x, y int) bool {
+	switch {
+	case e.Key == term.MouseWheelUp:
+		s.ScrollBy(0, -3)
+	case e.Key == term.MouseWheelDown:
+		s.ScrollBy(0, 3)
+	case e.Key == term.MouseLeft && s.vbar && x == s.viewW && y < s.viewH:
+		s.ScrollTo(s.x, y*s.contentH/max(1, s.viewH)-s.viewH/2)
+	case e.Key == term.MouseLeft && s.hbar && y == s.viewH && x < s.viewW:
+		s.ScrollTo(x*s.contentW/max(1, s.viewW)-s.viewW/2, s.y)
+	default:
+		return false
+	}
+	return true
+}
+
+// scrollCanvas is a canvas the size of the content that draws
+// only the part inside window, which is in content coordinates,
+// onto the view canvas.
+type scrollCanvas struct {
+	view Canvas
+	rect
+	window rect
+}
+
+func (canvas *scrollCanvas) unwrap() Canvas { return canvas.view }
+
+func (canvas *scrollCanvas) New(x, y, width, height int) Canvas {
+	return &scrollCanvas{
+		view:   canvas.view,
+		rect:   canvas.rect.subRect(x, y, width, height),
+		window: canvas.window,
+	}
+}
+
+// Base is on the screen, for the hit map
+func (canvas *scrollCanvas) Base() (int, int) {
+	x, y := canvas.view.Base()
+	return x + canvas.x - canvas.window.x, y + canvas.y - canvas.window.y
+}
+
+func (canvas *scrollCanvas) Draw(x, y int, ch rune, fg, bg uint16) {
+	if x < 0 || y < 0 || x >= canvas.width || y >= canvas.height {
+		return
+	}
+	x, y = canvas.x+x, canvas.y+y
+	if canvas.window.contains(x, y) {
+		canvas.view.Draw(x-canvas.window.x, y-canvas.window.y, ch, fg, bg)
+	}
+}
+
+func (canvas *scrollCanvas) DrawText(x, y int, s string, fg, bg uint16) {
+	for i, ch := range []rune(s) {
+		canvas.Draw(x+i, y, ch, fg, bg)
+	}
+}
+
+func (canvas *scrollCanvas) Clear() {
+	for x := 0; x < canvas.width; x++ {
+		for y := 0; y < canvas.height; y++ {
+			canvas.Draw(x, y, ' ', 0, 0)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestScrollViewport(t *testing.T) {
+	s := Scroll(Text("abcdef\nghijkl\nmnopqr\nstuvwx"))
+	canvas := NewStringCanvas(3, 2)
+	s.Render(canvas)
+	if w, h := s.ContentSize(); w != 6 || h != 4 {
+		t.Errorf("content size %dx%d", w, h)
+	}
+	s.ScrollTo(2, 1).Render(canvas)
+	if got := canvas.String(); got != "ijk\nopq\n" {
+		t.Errorf("at 2, 1: %q", got)
+	}
+	s.ScrollBy(10, 10).Render(canvas)
+	if x, y := s.Offset(); x != 3 || y != 2 {
+		t.Errorf("offset not clamped: %d, %d", x, y)
+	}
+	s.EnsureVisible(0, 1, 1, 1).Render(canvas)
+	if got := canvas.String(); got != "ghi\nmno\n" {
+		t.Errorf("after EnsureVisible: %q", got)
+	}
+}
+
+func TestScrollbars(t *testing.T) {
+	s := Scroll(Vlayer(TextLine("1"), TextLine("2"), TextLine("3"), TextLine("4"))).
+		SetScrollbars(true, false)
+	canvas := NewStringCanvas(2, 2)
+	s.Render(canvas)
+	if got := canvas.String(); got != "1█\n2│\n" {
+		t.Errorf("top: %q", got)
+	}
+	pressKey(s, term.KeyEnd, 0)
+	s.Render(canvas)
+	if got := canvas.String(); got != "3│\n4█\n" {
+		t.Errorf("bottom: %q", got)
+	}
+}
+
+func TestScrollMarkdownHeight(t *testing.T) {
+	s := Scroll(Markdown("one two three four"))
+	s.Render(NewStringCanvas(5, 2))
+	if w, h := s.ContentSize(); w != 5 || h != 4 {
+		t.Errorf("content size %dx%d", w, h)
+	}
+
+	framed := Scroll(SetColor(1, 0, Frame(SingleBorder, Markdown("one two three four"))))
+	framed.Render(NewStringCanvas(7, 2))
+	if w, h := framed.ContentSize(); w != 7 || h != 6 {
+		t.Errorf("framed content size %dx%d", w, h)
+	}
+}
+
+func TestScrollHitTest(t *testing.T) {
+	first, second := TextLine("first"), TextLine("second")
+	s := Scroll(Vlayer(first, second))
+	root := Vlayer(SizeH(1, s), TextLine("below"))
+	hits := NewHitMap()
+	hits.Render(root, NewStringCanvas(6, 2))
+	if x, y, _, _, _ := hits.Rect(second); x != 0 || y != 1 {
+		t.Errorf("second line at %d, %d", x, y)
+	}
+	for _, layer := range hits.HitTest(0, 1) {
+		if sameLayer(layer, s) || sameLayer(layer, second) {
+			t.Error("scrolled content hit outside the viewport")
+		}
+	}
+	s.ScrollTo(0, 1)
+	hits.Render(root, NewStringCanvas(6, 2))
+	if path := hits.HitTest(0, 0); !sameLayer(path[len(path)-2], second) {
+		t.Errorf("second line not hit after scrolling: %v", path)
+	}
+}
+
+func TestScrollFocus(t *testing.T) {
+	a := newTestFocusable("a")
+	text := Scroll(Text("1\n2\n3\n4"))
+	inner := newTestFocusable("b")
+	list := Scroll(Vlayer(inner, TextLine("c")))
+	root := Vlayer(a, SizeH(2, text), SizeH(1, list))
+
+	focus := NewFocus(root)
+	if got := focus.Focusables(); len(got) != 3 || !sameLayer(got[1], text) || got[2] != inner {
+		t.Fatalf("focusables: %v", got)
+	}
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyTab})
+	if !sameLayer(focus.Focused(), text) {
+		t.Fatalf("expected the text scroll to be focused")
+	}
+	focus.HandleEvent(term.Event{Type: term.EventKey, Key: term.KeyArrowDown})
+	canvas := NewStringCanvas(2, 2)
+	text.Render(canvas)
+	if got := canvas.String(); got != "2█\n3│\n" {
+		t.Errorf("focused scroll after down: %q", got)
+	}
+}
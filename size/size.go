@@ -151,6 +151,9 @@ func Sum(sizes []T) T {
 	return total
 }
 
+func (r RangeT) Min() int { return r.min }
+func (r RangeT) Max() int { return r.max }
+
 func (r RangeT) Length() int {
 	// return r.max
 	return zero(r.max - r.min + 1)
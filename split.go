@@ -0,0 +1,288 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+)
+
+type Direction int
+
+const (
+	// Horizontal puts layers side by side, as Hlayer does
+	Horizontal Direction = iota
+	// Vertical puts layers one above the other, as Vlayer does
+	Vertical
+)
+
+const (
+	collapseNone = iota
+	collapseFirst
+	collapseSecond
+)
+
+// SplitLayer shows two panes with a divider between them that the
+// user can move. The divider can be dragged with the mouse, or
+// focused and moved with the arrow keys. Home and End collapse the
+// first or the second pane, Enter shows it again.
+//
+// The first pane gets a ratio of the space, or a fixed number of
+// cells, but never less than the minimum of its size, and neither
+// does the second pane.
+type SplitLayer struct {
+	direction Direction
+	first     Layer
+	second    Layer
+	divider   *splitDivider
+
+	ratio     float64
+	position  int
+	absolute  bool
+	collapsed int
+
+	// from the last render
+	length    int
+	firstSize int
+}
+
+func Split(direction Direction, first, second Layer) *SplitLayer {
+	split := &SplitLayer{
+		direction: direction,
+		first:     wrapNil(first),
+		second:    wrapNil(second),
+		ratio:     0.5,
+	}
+	split.divider = &splitDivider{split: split}
+	return split
+}
+
+func (split *SplitLayer) First() Layer         { return split.first }
+func (split *SplitLayer) Second() Layer        { return split.second }
+func (split *SplitLayer) Direction() Direction { return split.direction }
+func (split *SplitLayer) Divider() Focusable   { return split.divider }
+func (split *SplitLayer) Position() int        { return split.firstSize }
+func (split *SplitLayer) Collapsed() (first, second bool) {
+	return split.collapsed == collapseFirst, split.collapsed == collapseSecond
+}
+
+// SetRatio gives the first pane the fraction ratio of the space,
+// so the panes keep their proportion when the space changes.
+func (split *SplitLayer) SetRatio(ratio float64) *SplitLayer {
+	split.ratio = max(0, min(ratio, 1))
+	split.absolute = false
+	return split
+}
+
+// SetPosition gives the first pane n cells, or when n is negative,
+// gives the second pane -n cells. The pane with the fixed size
+// keeps it when the space changes.
+func (split *SplitLayer) SetPosition(n int) *SplitLayer {
+	split.position = n
+	split.absolute = true
+	return split
+}
+
+func (split *SplitLayer) CollapseFirst() *SplitLayer {
+	split.collapsed = collapseFirst
+	return split
+}
+
+func (split *SplitLayer) CollapseSecond() *SplitLayer {
+	split.collapsed = collapseSecond
+	return split
+}
+
+// Restore shows the collapsed pane again, at its old size.
+func (split *SplitLayer) Restore() *SplitLayer {
+	split.collapsed = collapseNone
+	return split
+}
+
+// along returns the size of layer in the direction of the split
+func (split *SplitLayer) along(layer Layer) size.T {
+	if split.direction == Vertical {
+		return layer.Height()
+	}
+	return layer.Width()
+}
+
+func (split *SplitLayer) Width() size.T {
+	if split.direction == Vertical {
+		return size.Max([]size.T{split.first.Width(), split.second.Width()})
+	}
+	return size.Sum([]size.T{split.first.Width(), size.Const(1), split.second.Width()})
+}
+
+func (split *SplitLayer) Height() size.T {
+	if split.direction == Horizontal {
+		return size.Max([]size.T{split.first.Height(), split.second.Height()})
+	}
+	return size.Sum([]size.T{split.first.Height(), size.Const(1), split.second.Height()})
+}
+
+// minSize is the least a layer of size s can get
+func minSize(s size.T) int {
+	switch v := s.(type) {
+	case size.ConstT:
+		return int(v)
+	case size.RangeT:
+		return v.Min()
+	}
+	return 0
+}
+
+// sizeFirst returns how much of length, less the
+// divider, goes to the first pane
+func (split *SplitLayer) sizeFirst(length int) int {
+	room := max(0, length-1)
+	switch split.collapsed {
+	case collapseFirst:
+		return 0
+	case collapseSecond:
+		return room
+	}
+	n := int(split.ratio*float64(room) + 0.5)
+	if split.absolute {
+		n = split.position
+		if n < 0 {
+			n += room
+		}
+	}
+	n = clamp(n, minSize(split.along(split.first)), room-minSize(split.along(split.second)))
+	return clamp(n, 0, room)
+}
+
+// moveTo puts the divider n cells from the start,
+// keeping the ratio or the fixed pane as it was set
+func (split *SplitLayer) moveTo(n int) {
+	split.collapsed = collapseNone
+	room := max(0, split.length-1)
+	n = clamp(n, 0, room)
+	switch {
+	case !split.absolute:
+		if room > 0 {
+			split.ratio = float64(n) / float64(room)
+		}
+	case split.position < 0:
+		split.position = min(n-room, -1)
+	default:
+		split.position = n
+	}
+	split.firstSize = split.sizeFirst(split.length)
+}
+
+func (split *SplitLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	length := w
+	if split.direction == Vertical {
+		length = h
+	}
+	if length <= 0 {
+		return
+	}
+	n := split.sizeFirst(length)
+	split.length, split.firstSize = length, n
+	rest := max(0, length-n-1)
+
+	sub := func(start, extent int) Canvas {
+		if split.direction == Vertical {
+			return canvas.New(0, start, w, extent)
+		}
+		return canvas.New(start, 0, extent, h)
+	}
+	if split.collapsed != collapseFirst {
+		RenderChild(split.first, sub(0, n))
+	}
+	RenderChild(split.divider, sub(n, 1))
+	if split.collapsed != collapseSecond {
+		RenderChild(split.second, sub(n+1, rest))
+	}
+}
+
+func (split *SplitLayer) Children() []Layer {
+	return []Layer{split.first, split.divider, split.second}
+}
+
+// Shown leaves out a collapsed pane
+func (split *SplitLayer) Shown() []Layer {
+	var layers []Layer
+	if split.collapsed != collapseFirst {
+		layers = append(layers, split.first)
+	}
+	layers = append(layers, split.divider)
+	if split.collapsed != collapseSecond {
+		layers = append(layers, split.second)
+	}
+	return layers
+}
+
+type splitDivider struct {
+	split    *SplitLayer
+	focused  bool
+	dragging bool
+	dragFrom int
+}
+
+func (d *splitDivider) Width() size.T  { return size.Const(1) }
+func (d *splitDivider) Height() size.T { return size.Const(1) }
+
+func (d *splitDivider) SetFocused(focused bool) { d.focused = focused }
+
+func (d *splitDivider) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	ch := '│'
+	if d.split.direction == Vertical {
+		ch = '─'
+	}
+	var fg uint16
+	if d.focused || d.dragging {
+		fg = uint16(term.AttrReverse)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			canvas.Draw(x, y, ch, fg, 0)
+		}
+	}
+}
+
+func (d *splitDivider) HandleKey(e term.Event) bool {
+	split := d.split
+	back, forward := term.KeyArrowLeft, term.KeyArrowRight
+	if split.direction == Vertical {
+		back, forward = term.KeyArrowUp, term.KeyArrowDown
+	}
+	switch e.Key {
+	case back:
+		split.moveTo(split.firstSize - 1)
+	case forward:
+		split.moveTo(split.firstSize + 1)
+	case term.KeyHome:
+		split.CollapseFirst()
+	case term.KeyEnd:
+		split.CollapseSecond()
+	case term.KeyEnter:
+		split.Restore()
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleMouse drags the divider. The drag events come relative to
+// where the divider was at the press, so the offset is the move.
+func (d *splitDivider) HandleMouse(e term.Event, x, y int) bool {
+	offset := x
+	if d.split.direction == Vertical {
+		offset = y
+	}
+	switch {
+	case e.Key == term.MouseLeft && e.Mod&term.ModMotion == 0:
+		d.dragging, d.dragFrom = true, d.split.firstSize
+	case e.Key == term.MouseRelease:
+		d.dragging = false
+	case d.dragging && e.Mod&term.ModMotion != 0:
+		d.split.moveTo(d.dragFrom + offset)
+	default:
+		return false
+	}
+	return true
+}
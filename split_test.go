@@ -0,0 +1,104 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestSplitRender(t *testing.T) {
+	left := RenderLayer(func(canvas Canvas) { canvas.DrawText(0, 0, "aaaa", 0, 0) })
+	right := RenderLayer(func(canvas Canvas) { canvas.DrawText(0, 0, "bbbb", 0, 0) })
+	split := Split(Horizontal, left, right)
+
+	canvas := NewStringCanvas(9, 2)
+	split.Render(canvas)
+	want := "aaaa│bbbb\n" +
+		"    │    \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+
+	split.SetPosition(-2)
+	canvas = NewStringCanvas(9, 1)
+	split.Render(canvas)
+	if got := canvas.String(); got != "aaaa  │bb\n" {
+		t.Errorf("second pane should be 2 wide:\n%s", got)
+	}
+
+	split.CollapseSecond()
+	canvas = NewStringCanvas(9, 1)
+	split.Render(canvas)
+	if got := canvas.String(); got != "aaaa    │\n" {
+		t.Errorf("collapsed:\n%s", got)
+	}
+
+	vertical := Split(Vertical, SizeH(2, left), right).SetPosition(0)
+	canvas = NewStringCanvas(3, 5)
+	vertical.Render(canvas)
+	want = "aaa\n" +
+		"   \n" +
+		"───\n" +
+		"bbb\n" +
+		"   \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("the first pane should get its minimum:\n%s", got)
+	}
+}
+
+func TestSplitMove(t *testing.T) {
+	first, second := Button("a", nil), Button("b", nil)
+	split := Split(Horizontal, first, SizeW(20, second))
+	focus := NewFocus(split)
+	hits := NewHitMap()
+	hits.Render(split, NewStringCanvas(41, 3))
+	if split.Position() != 20 {
+		t.Fatalf("position %d", split.Position())
+	}
+
+	press := term.Event{Type: term.EventMouse, Key: term.MouseLeft, MouseX: 20, MouseY: 1}
+	hits.Dispatch(press)
+	drag := press
+	drag.Mod, drag.MouseX = term.ModMotion, 12
+	hits.Dispatch(drag)
+	hits.Dispatch(term.Event{Type: term.EventMouse, Key: term.MouseRelease, MouseX: 12, MouseY: 1})
+	hits.Render(split, NewStringCanvas(41, 3))
+	if split.Position() != 12 {
+		t.Errorf("drag should move the divider, position %d", split.Position())
+	}
+
+	drag.MouseX = 30
+	hits.Dispatch(drag)
+	if split.Position() != 12 {
+		t.Errorf("motion without a press should not move it")
+	}
+
+	focus.SetFocus(split.Divider())
+	arrow := func(key term.Key) { focus.HandleEvent(term.Event{Type: term.EventKey, Key: key}) }
+	arrow(term.KeyArrowRight)
+	arrow(term.KeyArrowRight)
+	if split.Position() != 14 {
+		t.Errorf("arrows should move the divider, position %d", split.Position())
+	}
+	for i := 0; i < 10; i++ {
+		arrow(term.KeyArrowRight)
+	}
+	if split.Position() != 20 {
+		t.Errorf("the second pane should keep its minimum, position %d", split.Position())
+	}
+
+	arrow(term.KeyHome)
+	if first, _ := split.Collapsed(); !first {
+		t.Fatalf("Home should collapse the first pane")
+	}
+	focus.Refresh()
+	focus.Next()
+	if focus.Focused() == Focusable(first) {
+		t.Errorf("the collapsed pane should not take the focus")
+	}
+	focus.SetFocus(split.Divider())
+	arrow(term.KeyEnter)
+	hits.Render(split, NewStringCanvas(41, 3))
+	if first, _ := split.Collapsed(); first || split.Position() != 20 {
+		t.Errorf("Enter should restore the pane, position %d", split.Position())
+	}
+}
@@ -0,0 +1,190 @@
+package wind
+
+import (
+	"github.com/nvlled/wind/size"
+	"sync"
+)
+
+type subscriber[T any] struct {
+	id int
+	fn func(value T)
+}
+
+// State is a value that layers show with Watch. Setting it rebuilds
+// the Watch layers of the state and clears the size caches of the
+// Hlayer, Vlayer and Zlayer layers around them, and only those, so
+// the layout follows the value without a ClearCache from the root.
+// When the layers are run by an App, the App redraws.
+//
+// Under an App, a State can be set from any goroutine, the caches
+// are cleared before the next render. Without the scheduler of an
+// App, set it on the goroutine that renders.
+type State[T any] struct {
+	mu      sync.Mutex
+	value   T
+	version int
+	subs    []subscriber[T]
+	nextID  int
+}
+
+func NewState[T any](value T) *State[T] {
+	return &State[T]{value: value}
+}
+
+func (s *State[T]) Get() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+func (s *State[T]) snapshot() (T, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value, s.version
+}
+
+// Set changes the value and calls the subscribers with it.
+func (s *State[T]) Set(value T) {
+	s.Update(func(T) T { return value })
+}
+
+// Update sets the value to fn of the old value. fn runs
+// with the state locked, and must not use the state.
+func (s *State[T]) Update(fn func(value T) T) {
+	s.mu.Lock()
+	s.value = fn(s.value)
+	s.version++
+	value := s.value
+	subs := append([]subscriber[T](nil), s.subs...)
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.fn(value)
+	}
+}
+
+// Subscribe calls fn with the value after each change, on the
+// goroutine that made it, until cancel is called.
+func (s *State[T]) Subscribe(fn func(value T)) (cancel func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subs = append(s.subs, subscriber[T]{id, fn})
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, sub := range s.subs {
+			if sub.id == id {
+				s.subs = append(s.subs[:i], s.subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Watch shows the layer that view makes of the value of state,
+// and makes it again when the value changes. Layers kept in the
+// made layer, like an Input, keep their state until then.
+//
+// The layer follows the state once rendered. It stops when a render
+// by a HitMap, as App does, no longer draws it, or when a Watch
+// around it makes its layer again, and starts again when drawn.
+// Without a HitMap, a Watch that is taken out of the layers in
+// other ways follows the state until the state is dropped.
+func Watch[T any](state *State[T], view func(value T) Layer) Layer {
+	return &watchLayer[T]{state: state, view: view}
+}
+
+type watchLayer[T any] struct {
+	state   *State[T]
+	view    func(value T) Layer
+	layer   Layer
+	version int
+	built   bool
+
+	mu     sync.Mutex
+	cancel func()
+	ref    layoutRef
+}
+
+// releaser is a layer that holds on to something, like a
+// subscription, until it is dropped from the layer tree
+type releaser interface {
+	release()
+}
+
+// release lets go of what the layers under layer hold
+func release(layer Layer) {
+	if r, ok := layer.(releaser); ok {
+		r.release()
+		return
+	}
+	if container, ok := layer.(Container); ok {
+		for _, child := range container.Children() {
+			if child != nil {
+				release(child)
+			}
+		}
+	}
+}
+
+func (w *watchLayer[T]) watched() Layer {
+	value, version := w.state.snapshot()
+	if !w.built || version != w.version {
+		old := w.layer
+		w.layer = wrapNil(w.view(value))
+		w.version, w.built = version, true
+		if old != nil {
+			release(old)
+		}
+	}
+	return w.layer
+}
+
+func (w *watchLayer[T]) release() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+	w.mu.Unlock()
+	if w.layer != nil {
+		release(w.layer)
+	}
+}
+
+// invalidate clears the caches around the layer, at once or,
+// when rendered by an App, before the next render
+func (w *watchLayer[T]) invalidate() {
+	w.ref.mu.Lock()
+	caches, scheduler := w.ref.caches, w.ref.scheduler
+	w.ref.mu.Unlock()
+	if scheduler != nil {
+		scheduler.invalidate(caches)
+		scheduler.Invalidate(w)
+		return
+	}
+	for _, cache := range caches {
+		cache.invalidate()
+	}
+}
+
+func (w *watchLayer[T]) Width() size.T     { return w.watched().Width() }
+func (w *watchLayer[T]) Height() size.T    { return w.watched().Height() }
+func (w *watchLayer[T]) Children() []Layer { return []Layer{w.watched()} }
+
+func (w *watchLayer[T]) HeightFor(width int) (int, bool) {
+	return heightFor(w.watched(), width)
+}
+
+func (w *watchLayer[T]) Render(canvas Canvas) {
+	w.ref.record(canvas)
+	w.mu.Lock()
+	if w.cancel == nil {
+		w.cancel = w.state.Subscribe(func(T) { w.invalidate() })
+	}
+	w.mu.Unlock()
+
+	RenderChild(w.watched(), canvas)
+}
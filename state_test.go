@@ -0,0 +1,108 @@
+package wind
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStateSubscribe(t *testing.T) {
+	state := NewState(1)
+	var got []int
+	cancel := state.Subscribe(func(value int) { got = append(got, value) })
+	state.Set(2)
+	state.Update(func(value int) int { return value * 10 })
+	cancel()
+	state.Set(3)
+	if len(got) != 2 || got[0] != 2 || got[1] != 20 {
+		t.Errorf("subscriber got %v", got)
+	}
+	if state.Get() != 3 {
+		t.Errorf("value %d", state.Get())
+	}
+}
+
+func TestStateWatch(t *testing.T) {
+	label := NewState("ab")
+	watched := Hlayer(Watch(label, func(text string) Layer { return Text(text) }), Text("|"))
+	other := Hlayer(Text("cd"), Text("|"))
+	root := Vlayer(watched, other)
+
+	canvas := NewStringCanvas(6, 2)
+	root.Render(canvas)
+	if got := canvas.String(); got != "ab|   \ncd|   \n" {
+		t.Fatalf("render:\n%s", got)
+	}
+
+	label.Set("abcd")
+	if watched.(*cacheLayer).sizeCached || root.(*cacheLayer).sizeCached {
+		t.Errorf("the caches around the watch layer should be cleared")
+	}
+	if !other.(*cacheLayer).sizeCached {
+		t.Errorf("other caches should be kept")
+	}
+	canvas = NewStringCanvas(6, 2)
+	root.Render(canvas)
+	if got := canvas.String(); got != "abcd| \ncd|   \n" {
+		t.Errorf("render after set:\n%s", got)
+	}
+}
+
+func TestStateWatchScheduled(t *testing.T) {
+	count := NewState(1)
+	row := Hlayer(Watch(count, func(n int) Layer { return Text(strings.Repeat("x", n)) }), Text("|"))
+	s := NewScheduler()
+	row.Render(WithScheduler(NewStringCanvas(5, 1), s))
+
+	count.Set(3)
+	if _, pending := s.Next(); !pending {
+		t.Errorf("set should ask for a redraw")
+	}
+	if !row.(*cacheLayer).sizeCached {
+		t.Errorf("the cache should be kept until the flush")
+	}
+	s.Flush()
+	canvas := NewStringCanvas(5, 1)
+	row.Render(WithScheduler(canvas, s))
+	if got := canvas.String(); got != "xxx| \n" {
+		t.Errorf("render after flush: %q", got)
+	}
+}
+
+func TestStateWatchRelease(t *testing.T) {
+	outer, inner := NewState(1), NewState("x")
+	root := Vlayer(Watch(outer, func(n int) Layer {
+		return Watch(inner, func(text string) Layer { return Text(text) })
+	}))
+	if len(inner.subs) != 0 {
+		t.Fatalf("watch layers should subscribe when rendered")
+	}
+	for i := 2; i < 5; i++ {
+		root.Render(NewStringCanvas(3, 1))
+		outer.Set(i)
+	}
+	root.Render(NewStringCanvas(3, 1))
+	if len(inner.subs) != 1 || len(outer.subs) != 1 {
+		t.Errorf("dropped watch layers should unsubscribe: %d, %d", len(inner.subs), len(outer.subs))
+	}
+}
+
+func TestStateWatchLeavesRender(t *testing.T) {
+	state := NewState("x")
+	tab := Tab().SetElements(Watch(state, func(text string) Layer { return Text(text) }), Text("other"))
+	hits := NewHitMap()
+	hits.Render(tab.ShowIndex(0), NewStringCanvas(5, 1))
+	if len(state.subs) != 1 {
+		t.Fatalf("a drawn watch layer should follow the state")
+	}
+	hits.Render(tab.ShowIndex(1), NewStringCanvas(5, 1))
+	if len(state.subs) != 0 {
+		t.Errorf("a watch layer that is not drawn should stop following")
+	}
+	canvas := NewStringCanvas(5, 1)
+	hits.Render(tab.ShowIndex(0), canvas)
+	state.Set("y")
+	hits.Render(tab, canvas)
+	if len(state.subs) != 1 || canvas.String() != "y    \n" {
+		t.Errorf("drawn again, it should follow again: %q", canvas.String())
+	}
+}
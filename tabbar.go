@@ -0,0 +1,57 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"unicode/utf8"
+)
+
+// TabBar is a one line header with the titles of the
+// tabs of tab, the shown one highlighted. Clicking on
+// a title shows its tab.
+func TabBar(tab TabLayer) Layer {
+	return &tabBar{tab: tab}
+}
+
+type tabBar struct {
+	tab TabLayer
+
+	// where each title starts and ends, from the last render
+	starts, ends []int
+}
+
+func (bar *tabBar) Width() size.T  { return size.Free }
+func (bar *tabBar) Height() size.T { return size.Const(1) }
+
+func (bar *tabBar) Render(canvas Canvas) {
+	index := bar.tab.Index()
+	bar.starts, bar.ends = bar.starts[:0], bar.ends[:0]
+	x := 0
+	for i, title := range bar.tab.Titles() {
+		if i > 0 {
+			canvas.Draw(x, 0, '│', 0, 0)
+			x++
+		}
+		var fg uint16
+		if i == index {
+			fg = uint16(term.AttrReverse | term.AttrBold)
+		}
+		bar.starts = append(bar.starts, x)
+		canvas.DrawText(x, 0, " "+title+" ", fg, 0)
+		x += utf8.RuneCountInString(title) + 2
+		bar.ends = append(bar.ends, x)
+	}
+}
+
+func (bar *tabBar) HandleMouse(e term.Event, x, y int) bool {
+	if e.Key != term.MouseLeft || e.Mod&term.ModMotion != 0 {
+		return false
+	}
+	for i := range bar.starts {
+		if x >= bar.starts[i] && x < bar.ends[i] {
+			bar.tab.ShowIndex(i)
+			return true
+		}
+	}
+	return false
+}
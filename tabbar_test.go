@@ -0,0 +1,95 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestTabTitles(t *testing.T) {
+	tab := Tab()
+	tab.SetElements(
+		tab.Name("one", Text("1")),
+		Text("2"),
+		tab.Name("three", Text("3")),
+	).AddTab("four", Text("4"))
+	titles := tab.Titles()
+	if len(titles) != 4 || titles[0] != "one" || titles[1] != "2" ||
+		titles[2] != "three" || titles[3] != "four" {
+		t.Errorf("titles: %v", titles)
+	}
+
+	if tab.Index() != 0 {
+		t.Errorf("first tab should be shown, got %d", tab.Index())
+	}
+	tab.ShowName("three")
+	if tab.Index() != 2 {
+		t.Errorf("ShowName: index %d", tab.Index())
+	}
+	tab.Next().Next()
+	if tab.Index() != 0 {
+		t.Errorf("Next should go around, index %d", tab.Index())
+	}
+	tab.Prev()
+	if tab.Index() != 3 {
+		t.Errorf("Prev should go around, index %d", tab.Index())
+	}
+}
+
+func TestTabIndexOfFuncLayers(t *testing.T) {
+	tab := Tab().SetElements(CharBlock('1'), CharBlock('2')).ShowIndex(1)
+	if tab.Index() != 1 {
+		t.Errorf("index %d", tab.Index())
+	}
+	if tab.Next().Index() != 0 || tab.Prev().Index() != 1 {
+		t.Errorf("Next and Prev should move, index %d", tab.Index())
+	}
+
+	tab = Tab().AddTab("a", RenderLayer(func(Canvas) {})).AddTab("b", CharBlock('b'))
+	if titles := tab.Titles(); titles[0] != "a" || titles[1] != "b" {
+		t.Errorf("titles %v", titles)
+	}
+	canvas := NewStringCanvas(8, 1)
+	TabBar(tab.ShowIndex(1)).Render(canvas)
+	if got := canvas.String(); got != " a │ b  \n" {
+		t.Errorf("render: %q", got)
+	}
+	if tab.ShowName("a").Index() != 0 || tab.ShowName("b").Index() != 1 {
+		t.Errorf("ShowName should give the index of the tab, got %d", tab.Index())
+	}
+	if tab.ShowName("none").Index() != -1 {
+		t.Errorf("an unknown name has no tab, got %d", tab.Index())
+	}
+}
+
+func TestTabTitleOfManyNames(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		elem := Text("x")
+		tab := Tab()
+		tab.Name("first", elem)
+		tab.Name("second", elem)
+		tab.SetElements(elem)
+		if title := tab.Titles()[0]; title != "first" {
+			t.Fatalf("title %q, want the first name", title)
+		}
+	}
+}
+
+func TestTabBar(t *testing.T) {
+	tab := Tab().AddTab("a", Text("A")).AddTab("bb", Text("B"))
+	bar := TabBar(tab)
+	hits := NewHitMap()
+	canvas := NewStringCanvas(10, 1)
+	hits.Render(bar, canvas)
+	if got := canvas.String(); got != " a │ bb   \n" {
+		t.Errorf("render: %q", got)
+	}
+	hits.Dispatch(term.Event{Type: term.EventMouse, Key: term.MouseLeft, MouseX: 5})
+	if tab.Index() != 1 {
+		t.Errorf("click should show the second tab, index %d", tab.Index())
+	}
+	tab.ShowIndex(0)
+	hits.Dispatch(term.Event{Type: term.EventMouse, Key: term.MouseLeft, MouseX: 9})
+	if tab.Index() != 0 {
+		t.Errorf("click past the titles should not change the tab, index %d", tab.Index())
+	}
+}
@@ -0,0 +1,500 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+	"strings"
+)
+
+type TextPos struct {
+	Row, Col int
+}
+
+func (p TextPos) before(q TextPos) bool {
+	return p.Row < q.Row || p.Row == q.Row && p.Col < q.Col
+}
+
+// TextAreaLayer is a multi line text editor. It takes the same
+// movement and deletion keys as InputLayer, plus Enter, PgUp and
+// PgDn. Ctrl-Space sets the mark, selecting the text between the
+// mark and the cursor, Esc or Ctrl-g drop it. Ctrl-z undoes and
+// Ctrl-y redoes. Tab is left to the parent layers.
+type TextAreaLayer struct {
+	lines   [][]rune
+	cursor  TextPos
+	goalCol int
+	mark    *TextPos
+	wrap    bool
+	focused bool
+
+	// scroll position, in screen lines and columns
+	offsetY int
+	offsetX int
+	// size of the last render
+	width  int
+	height int
+
+	undo     []textSnapshot
+	redo     []textSnapshot
+	lastEdit editKind
+
+	onChange func(text string)
+}
+
+type textSnapshot struct {
+	lines  [][]rune
+	cursor TextPos
+}
+
+type editKind int
+
+const (
+	editNone editKind = iota
+	editInsert
+	editDelete
+	editOther
+)
+
+func TextArea() *TextAreaLayer {
+	return &TextAreaLayer{
+		lines:   [][]rune{{}},
+		goalCol: -1,
+		width:   80,
+		height:  24,
+	}
+}
+
+func (ta *TextAreaLayer) Text() string {
+	lines := make([]string, len(ta.lines))
+	for i, line := range ta.lines {
+		lines[i] = string(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Value is the same as Text.
+func (ta *TextAreaLayer) Value() string { return ta.Text() }
+
+// SetText replaces the text and clears the undo history.
+func (ta *TextAreaLayer) SetText(text string) *TextAreaLayer {
+	ta.lines = nil
+	for _, line := range strings.Split(text, "\n") {
+		ta.lines = append(ta.lines, []rune(line))
+	}
+	ta.cursor = TextPos{}
+	ta.mark = nil
+	ta.undo, ta.redo = nil, nil
+	ta.lastEdit = editNone
+	return ta
+}
+
+// SetWrap switches between soft wrapping long lines
+// and scrolling them horizontally.
+func (ta *TextAreaLayer) SetWrap(wrap bool) *TextAreaLayer {
+	ta.wrap = wrap
+	ta.offsetX = 0
+	return ta
+}
+
+func (ta *TextAreaLayer) OnChange(handler func(text string)) *TextAreaLayer {
+	ta.onChange = handler
+	return ta
+}
+
+func (ta *TextAreaLayer) Cursor() TextPos { return ta.cursor }
+
+func (ta *TextAreaLayer) SetCursor(pos TextPos) *TextAreaLayer {
+	ta.cursor = ta.clampPos(pos)
+	ta.goalCol = -1
+	return ta
+}
+
+// Select sets the mark at from and moves the cursor to to.
+func (ta *TextAreaLayer) Select(from, to TextPos) *TextAreaLayer {
+	from = ta.clampPos(from)
+	ta.mark = &from
+	return ta.SetCursor(to)
+}
+
+func (ta *TextAreaLayer) ClearSelection() *TextAreaLayer {
+	ta.mark = nil
+	return ta
+}
+
+// Selection returns the ordered ends of the selection.
+func (ta *TextAreaLayer) Selection() (from, to TextPos, ok bool) {
+	if ta.mark == nil {
+		return from, to, false
+	}
+	from, to = *ta.mark, ta.cursor
+	if to.before(from) {
+		from, to = to, from
+	}
+	return from, to, true
+}
+
+func (ta *TextAreaLayer) SelectedText() string {
+	from, to, ok := ta.Selection()
+	if !ok {
+		return ""
+	}
+	return ta.textRange(from, to)
+}
+
+func (ta *TextAreaLayer) textRange(from, to TextPos) string {
+	if from.Row == to.Row {
+		return string(ta.lines[from.Row][from.Col:to.Col])
+	}
+	parts := []string{string(ta.lines[from.Row][from.Col:])}
+	for row := from.Row + 1; row < to.Row; row++ {
+		parts = append(parts, string(ta.lines[row]))
+	}
+	parts = append(parts, string(ta.lines[to.Row][:to.Col]))
+	return strings.Join(parts, "\n")
+}
+
+func (ta *TextAreaLayer) clampPos(pos TextPos) TextPos {
+	pos.Row = clamp(pos.Row, 0, len(ta.lines)-1)
+	pos.Col = clamp(pos.Col, 0, len(ta.lines[pos.Row]))
+	return pos
+}
+
+func (ta *TextAreaLayer) snapshot() textSnapshot {
+	lines := make([][]rune, len(ta.lines))
+	for i, line := range ta.lines {
+		lines[i] = append([]rune{}, line...)
+	}
+	return textSnapshot{lines, ta.cursor}
+}
+
+// record saves the text for undo. Runs of typing
+// or of deleting are undone as one edit.
+func (ta *TextAreaLayer) record(kind editKind) {
+	if kind == ta.lastEdit && kind != editOther {
+		return
+	}
+	ta.undo = append(ta.undo, ta.snapshot())
+	ta.redo = nil
+	ta.lastEdit = kind
+}
+
+func (ta *TextAreaLayer) restore(from *[]textSnapshot, to *[]textSnapshot) bool {
+	n := len(*from)
+	if n == 0 {
+		return false
+	}
+	*to = append(*to, ta.snapshot())
+	s := (*from)[n-1]
+	*from = (*from)[:n-1]
+	ta.lines, ta.cursor = s.lines, s.cursor
+	ta.mark = nil
+	ta.lastEdit = editNone
+	ta.changed()
+	return true
+}
+
+func (ta *TextAreaLayer) Undo() bool { return ta.restore(&ta.undo, &ta.redo) }
+func (ta *TextAreaLayer) Redo() bool { return ta.restore(&ta.redo, &ta.undo) }
+
+func (ta *TextAreaLayer) changed() {
+	ta.goalCol = -1
+	if ta.onChange != nil {
+		ta.onChange(ta.Text())
+	}
+}
+
+func (ta *TextAreaLayer) deleteRange(from, to TextPos) {
+	if to.before(from) {
+		from, to = to, from
+	}
+	head := ta.lines[from.Row][:from.Col:from.Col]
+	line := append(head, ta.lines[to.Row][to.Col:]...)
+	ta.lines = append(ta.lines[:from.Row+1], ta.lines[to.Row+1:]...)
+	ta.lines[from.Row] = line
+	ta.cursor = from
+}
+
+// deleteSelection reports whether there was a selection to delete
+func (ta *TextAreaLayer) deleteSelection() bool {
+	from, to, ok := ta.Selection()
+	ta.mark = nil
+	if !ok || from == to {
+		return false
+	}
+	ta.record(editOther)
+	ta.deleteRange(from, to)
+	return true
+}
+
+func (ta *TextAreaLayer) Insert(text string) {
+	kind := editInsert
+	if strings.ContainsAny(text, " \n") {
+		kind = editOther
+	}
+	if !ta.deleteSelection() {
+		ta.record(kind)
+	}
+	row, col := ta.cursor.Row, ta.cursor.Col
+	tail := append([]rune{}, ta.lines[row][col:]...)
+	parts := strings.Split(text, "\n")
+	ta.lines[row] = append(ta.lines[row][:col], []rune(parts[0])...)
+	for _, part := range parts[1:] {
+		row++
+		ta.lines = append(ta.lines[:row], append([][]rune{[]rune(part)}, ta.lines[row:]...)...)
+	}
+	col = len(ta.lines[row])
+	ta.lines[row] = append(ta.lines[row], tail...)
+	ta.cursor = TextPos{row, col}
+	ta.changed()
+}
+
+// delete removes text between the cursor and the position
+// found by move, or the selection if there is one
+func (ta *TextAreaLayer) delete(move func(TextPos) TextPos) {
+	if !ta.deleteSelection() {
+		to := move(ta.cursor)
+		if to == ta.cursor {
+			return
+		}
+		ta.record(editDelete)
+		ta.deleteRange(ta.cursor, to)
+	}
+	ta.changed()
+}
+
+func (ta *TextAreaLayer) prevPos(pos TextPos) TextPos {
+	if pos.Col > 0 {
+		return TextPos{pos.Row, pos.Col - 1}
+	}
+	if pos.Row > 0 {
+		return TextPos{pos.Row - 1, len(ta.lines[pos.Row-1])}
+	}
+	return pos
+}
+
+func (ta *TextAreaLayer) nextPos(pos TextPos) TextPos {
+	if pos.Col < len(ta.lines[pos.Row]) {
+		return TextPos{pos.Row, pos.Col + 1}
+	}
+	if pos.Row < len(ta.lines)-1 {
+		return TextPos{pos.Row + 1, 0}
+	}
+	return pos
+}
+
+func (ta *TextAreaLayer) prevWord(pos TextPos) TextPos {
+	if pos.Col == 0 {
+		return ta.prevPos(pos)
+	}
+	return TextPos{pos.Row, wordLeft(ta.lines[pos.Row], pos.Col)}
+}
+
+func (ta *TextAreaLayer) nextWord(pos TextPos) TextPos {
+	if pos.Col == len(ta.lines[pos.Row]) {
+		return ta.nextPos(pos)
+	}
+	return TextPos{pos.Row, wordRight(ta.lines[pos.Row], pos.Col)}
+}
+
+// a screen line: part of the text line row,
+// from column start up to end
+type visualLine struct {
+	row, start, end int
+}
+
+// breakLine returns where each screen line of line starts,
+// breaking after spaces when a word does not fit.
+func breakLine(line []rune, width int) []int {
+	starts := []int{0}
+	start := 0
+	for len(line)-start >= width {
+		end := start + width
+		for i := end; i > start; i-- {
+			if line[i-1] == ' ' {
+				end = i
+				break
+			}
+		}
+		starts = append(starts, end)
+		start = end
+	}
+	return starts
+}
+
+func (ta *TextAreaLayer) visualLines() []visualLine {
+	var result []visualLine
+	for row, line := range ta.lines {
+		if !ta.wrap || ta.width < 1 {
+			result = append(result, visualLine{row, 0, len(line)})
+			continue
+		}
+		starts := breakLine(line, ta.width)
+		for i, start := range starts {
+			end := len(line)
+			if i+1 < len(starts) {
+				end = starts[i+1]
+			}
+			result = append(result, visualLine{row, start, end})
+		}
+	}
+	return result
+}
+
+// visualPos finds the screen line and column of pos. A position
+// at a wrapping point is shown at the start of the next line.
+func visualPos(lines []visualLine, pos TextPos) (int, int) {
+	for i, vl := range lines {
+		last := i+1 == len(lines) || lines[i+1].row != vl.row
+		if vl.row == pos.Row && pos.Col >= vl.start && (pos.Col < vl.end || last) {
+			return i, pos.Col - vl.start
+		}
+	}
+	return 0, 0
+}
+
+// moveLines moves the cursor by n screen lines,
+// keeping to the column where vertical movement began
+func (ta *TextAreaLayer) moveLines(n int) {
+	lines := ta.visualLines()
+	y, x := visualPos(lines, ta.cursor)
+	if ta.goalCol < 0 {
+		ta.goalCol = x
+	}
+	y = clamp(y+n, 0, len(lines)-1)
+	vl := lines[y]
+	col := min(vl.start+ta.goalCol, vl.end)
+	last := y+1 == len(lines) || lines[y+1].row != vl.row
+	if col == vl.end && !last && col > vl.start {
+		col-- // stay on this screen line
+	}
+	ta.cursor = TextPos{vl.row, col}
+}
+
+func (ta *TextAreaLayer) Width() size.T  { return size.Free }
+func (ta *TextAreaLayer) Height() size.T { return size.Free }
+
+func (ta *TextAreaLayer) SetFocused(focused bool) { ta.focused = focused }
+
+func (ta *TextAreaLayer) Render(canvas Canvas) {
+	w, h := canvas.Dimension()
+	if w <= 0 || h <= 0 {
+		return
+	}
+	ta.width, ta.height = w, h
+	lines := ta.visualLines()
+	cy, cx := visualPos(lines, ta.cursor)
+
+	// follow the cursor
+	ta.offsetY = clamp(ta.offsetY, cy-h+1, cy)
+	ta.offsetY = clamp(ta.offsetY, 0, max(0, len(lines)-1))
+	if ta.wrap {
+		ta.offsetX = 0
+	} else {
+		ta.offsetX = clamp(ta.offsetX, cx-w+1, cx)
+	}
+
+	from, to, selecting := ta.Selection()
+	for y := 0; y < h; y++ {
+		i := ta.offsetY + y
+		for x := 0; x < w; x++ {
+			ch := ' '
+			var fg, bg uint16
+			if i < len(lines) {
+				vl := lines[i]
+				col := vl.start + ta.offsetX + x
+				if col < vl.end {
+					ch = ta.lines[vl.row][col]
+				}
+				pos := TextPos{vl.row, col}
+				if selecting && !pos.before(from) && pos.before(to) && col <= vl.end {
+					bg = uint16(term.ColorBlue)
+				}
+				if ta.focused && i == cy && x == cx-ta.offsetX {
+					fg = uint16(term.AttrReverse)
+				}
+			}
+			canvas.Draw(x, y, ch, fg, bg)
+		}
+	}
+}
+
+func (ta *TextAreaLayer) HandleKey(e term.Event) bool {
+	moved := func(pos TextPos) {
+		ta.cursor = pos
+		ta.goalCol = -1
+		ta.lastEdit = editNone
+	}
+	if e.Mod&term.ModAlt != 0 {
+		switch {
+		case e.Ch == 'b' || e.Key == term.KeyArrowLeft:
+			moved(ta.prevWord(ta.cursor))
+		case e.Ch == 'f' || e.Key == term.KeyArrowRight:
+			moved(ta.nextWord(ta.cursor))
+		case e.Ch == 'd':
+			ta.delete(ta.nextWord)
+		case e.Key == term.KeyBackspace || e.Key == term.KeyBackspace2:
+			ta.delete(ta.prevWord)
+		default:
+			return false
+		}
+		return true
+	}
+	if e.Ch != 0 {
+		ta.Insert(string(e.Ch))
+		return true
+	}
+
+	row := ta.cursor.Row
+	switch e.Key {
+	case term.KeySpace:
+		ta.Insert(" ")
+	case term.KeyEnter:
+		ta.Insert("\n")
+	case term.KeyBackspace, term.KeyBackspace2:
+		ta.delete(ta.prevPos)
+	case term.KeyDelete, term.KeyCtrlD:
+		ta.delete(ta.nextPos)
+	case term.KeyCtrlW:
+		ta.delete(ta.prevWord)
+	case term.KeyCtrlK:
+		ta.delete(func(pos TextPos) TextPos {
+			if pos.Col == len(ta.lines[row]) {
+				return ta.nextPos(pos)
+			}
+			return TextPos{row, len(ta.lines[row])}
+		})
+	case term.KeyCtrlU:
+		ta.delete(func(pos TextPos) TextPos { return TextPos{row, 0} })
+	case term.KeyArrowLeft, term.KeyCtrlB:
+		moved(ta.prevPos(ta.cursor))
+	case term.KeyArrowRight, term.KeyCtrlF:
+		moved(ta.nextPos(ta.cursor))
+	case term.KeyHome, term.KeyCtrlA:
+		moved(TextPos{row, 0})
+	case term.KeyEnd, term.KeyCtrlE:
+		moved(TextPos{row, len(ta.lines[row])})
+	case term.KeyArrowUp, term.KeyCtrlP:
+		ta.moveLines(-1)
+	case term.KeyArrowDown, term.KeyCtrlN:
+		ta.moveLines(1)
+	case term.KeyPgup:
+		ta.moveLines(-max(1, ta.height-1))
+	case term.KeyPgdn:
+		ta.moveLines(max(1, ta.height-1))
+	case term.KeyCtrlSpace:
+		pos := ta.cursor
+		ta.mark = &pos
+	case term.KeyEsc, term.KeyCtrlG:
+		if ta.mark == nil {
+			return false
+		}
+		ta.mark = nil
+	case term.KeyCtrlZ:
+		ta.Undo()
+	case term.KeyCtrlY:
+		ta.Redo()
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestTextAreaEditing(t *testing.T) {
+	ta := TextArea()
+	typeKeys(ta, "first")
+	pressKey(ta, term.KeyEnter, 0)
+	typeKeys(ta, "second line")
+	pressKey(ta, term.KeyArrowUp, 0)
+	if ta.Cursor() != (TextPos{0, 5}) {
+		t.Errorf("cursor = %v", ta.Cursor())
+	}
+	pressKey(ta, term.KeyHome, 0)
+	pressKey(ta, term.KeyCtrlSpace, 0)
+	pressKey(ta, term.KeyArrowDown, 0)
+	pressKey(ta, term.KeyArrowRight, 0)
+	if got := ta.SelectedText(); got != "first\ns" {
+		t.Errorf("selection = %q", got)
+	}
+	typeKeys(ta, "S")
+	if ta.Text() != "Second line" {
+		t.Errorf("typing should replace the selection: %q", ta.Text())
+	}
+	ta.Undo()
+	if ta.Text() != "first\nsecond line" {
+		t.Errorf("undo: %q", ta.Text())
+	}
+	for ta.Undo() {
+	}
+	if ta.Text() != "" {
+		t.Errorf("undo to start: %q", ta.Text())
+	}
+	for ta.Redo() {
+	}
+	if ta.Text() != "Second line" {
+		t.Errorf("redo: %q", ta.Text())
+	}
+	pressKey(ta, term.KeyEnd, 0)
+	pressKey(ta, term.KeyBackspace2, 0)
+	pressKey(ta, term.KeyBackspace2, 0)
+	pressKey(ta, term.KeyCtrlW, 0)
+	if ta.Text() != "Second " {
+		t.Errorf("delete: %q", ta.Text())
+	}
+}
+
+func TestTextAreaWrap(t *testing.T) {
+	ta := TextArea().SetWrap(true)
+	ta.SetText("the quick brown fox jumps\nover")
+	ta.SetFocused(true)
+	canvas := NewStringCanvas(10, 2)
+	ta.Render(canvas)
+	if got := canvas.String(); got != "the quick \nbrown fox \n" {
+		t.Errorf("wrapped: %q", got)
+	}
+	pressKey(ta, term.KeyArrowDown, 0)
+	pressKey(ta, term.KeyArrowDown, 0)
+	pressKey(ta, term.KeyArrowDown, 0)
+	ta.Render(canvas)
+	if ta.Cursor() != (TextPos{1, 0}) || canvas.String() != "jumps     \nover      \n" {
+		t.Errorf("scrolled to %v: %q", ta.Cursor(), canvas.String())
+	}
+}
@@ -0,0 +1,313 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"github.com/nvlled/wind/size"
+)
+
+// TreeNode is a node of a TreeLayer. Children is only
+// called for expanded nodes, so it can load them lazily.
+type TreeNode interface {
+	Label() string
+	Children() []TreeNode
+
+	// IsLeaf tells whether the node can be expanded,
+	// without loading the children.
+	IsLeaf() bool
+	Expanded() bool
+	SetExpanded(expanded bool)
+}
+
+// TreeItem is a TreeNode with given children, or with children
+// loaded by a function the first time they are needed.
+type TreeItem struct {
+	label    string
+	children []TreeNode
+	load     func() []TreeNode
+	expanded bool
+}
+
+func NewTreeItem(label string, children ...TreeNode) *TreeItem {
+	return &TreeItem{label: label, children: children}
+}
+
+func LazyTreeItem(label string, load func() []TreeNode) *TreeItem {
+	return &TreeItem{label: label, load: load}
+}
+
+func (item *TreeItem) Label() string { return item.label }
+
+func (item *TreeItem) Children() []TreeNode {
+	if item.load != nil {
+		item.children = item.load()
+		item.load = nil
+	}
+	return item.children
+}
+
+func (item *TreeItem) IsLeaf() bool {
+	return item.load == nil && len(item.children) == 0
+}
+
+func (item *TreeItem) Expanded() bool { return item.expanded }
+
+func (item *TreeItem) SetExpanded(expanded bool) { item.expanded = expanded }
+
+// TreeLayer shows the expanded part of a tree, one node per line,
+// with guide lines from the nodes to their children. It is as high
+// as the number of shown nodes, and scrolls to the selected node
+// when it gets less.
+//
+// Up and Down, or k and j, select a node. Right or l expands the
+// selected node, or goes to its first child, and Left or h collapses
+// it, or goes to its parent. Space toggles the selected node, and
+// so does Enter unless there is an OnSubmit handler.
+//
+// The shown nodes are kept until the tree changes them. After
+// changing the nodes some other way, call Refresh.
+type TreeLayer struct {
+	roots    []TreeNode
+	selected int
+	offset   int
+	focused  bool
+	height   int
+
+	shown []treeRow
+	ref   layoutRef
+
+	onChange func(node TreeNode)
+	onSubmit func(node TreeNode)
+}
+
+func Tree(roots ...TreeNode) *TreeLayer {
+	return &TreeLayer{roots: roots, height: 1}
+}
+
+type treeRow struct {
+	node   TreeNode
+	parent int
+	guides string
+}
+
+// rows returns the shown nodes
+func (tree *TreeLayer) rows() []treeRow {
+	if tree.shown == nil {
+		tree.shown = tree.walk()
+	}
+	return tree.shown
+}
+
+// Refresh shows the changes made to the nodes outside the tree,
+// like nodes added or expanded. The tree grows or shrinks with them.
+func (tree *TreeLayer) Refresh() *TreeLayer {
+	tree.shown = nil
+	tree.ref.clear()
+	return tree.Select(tree.selected)
+}
+
+func (tree *TreeLayer) walk() []treeRow {
+	rows := []treeRow{}
+	var walk func(nodes []TreeNode, parent int, indent string)
+	walk = func(nodes []TreeNode, parent int, indent string) {
+		for i, node := range nodes {
+			guides, childIndent := indent, indent
+			if parent >= 0 {
+				if i == len(nodes)-1 {
+					guides, childIndent = indent+"└─ ", indent+"   "
+				} else {
+					guides, childIndent = indent+"├─ ", indent+"│  "
+				}
+			}
+			rows = append(rows, treeRow{node, parent, guides})
+			if node.Expanded() && !node.IsLeaf() {
+				walk(node.Children(), len(rows)-1, childIndent)
+			}
+		}
+	}
+	walk(tree.roots, -1, "")
+	return rows
+}
+
+func (tree *TreeLayer) Roots() []TreeNode { return tree.roots }
+
+func (tree *TreeLayer) SetRoots(roots ...TreeNode) *TreeLayer {
+	tree.roots = roots
+	return tree.Refresh()
+}
+
+// Selected returns the selected node, or nil for an empty tree.
+func (tree *TreeLayer) Selected() TreeNode {
+	rows := tree.rows()
+	if tree.selected >= len(rows) {
+		return nil
+	}
+	return rows[tree.selected].node
+}
+
+// Select selects the node on the index-th line.
+func (tree *TreeLayer) Select(index int) *TreeLayer {
+	rows := tree.rows()
+	index = clamp(index, 0, max(0, len(rows)-1))
+	if index != tree.selected {
+		tree.selected = index
+		if tree.onChange != nil {
+			tree.onChange(rows[index].node)
+		}
+	}
+	return tree
+}
+
+func (tree *TreeLayer) OnChange(handler func(node TreeNode)) *TreeLayer {
+	tree.onChange = handler
+	return tree
+}
+
+func (tree *TreeLayer) OnSubmit(handler func(node TreeNode)) *TreeLayer {
+	tree.onSubmit = handler
+	return tree
+}
+
+func (tree *TreeLayer) Width() size.T  { return size.Free }
+func (tree *TreeLayer) Height() size.T { return size.Const(len(tree.rows())) }
+
+func (tree *TreeLayer) SetFocused(focused bool) { tree.focused = focused }
+
+func (tree *TreeLayer) Render(canvas Canvas) {
+	tree.ref.record(canvas)
+	w, h := canvas.Dimension()
+	if h <= 0 {
+		return
+	}
+	tree.height = h
+	rows := tree.rows()
+	n := len(rows)
+	tree.selected = clamp(tree.selected, 0, max(0, n-1))
+	tree.offset = clamp(tree.offset, tree.selected-h+1, tree.selected)
+	tree.offset = clamp(tree.offset, 0, max(0, n-h))
+
+	for y := 0; y < h && tree.offset+y < n; y++ {
+		i := tree.offset + y
+		row := rows[i]
+		x := 0
+		for _, ch := range row.guides {
+			canvas.Draw(x, y, ch, 0, 0)
+			x++
+		}
+		text := row.node.Label()
+		switch {
+		case row.node.IsLeaf():
+		case row.node.Expanded():
+			text = "▾ " + text
+		default:
+			text = "▸ " + text
+		}
+		var fg uint16
+		if i == tree.selected {
+			fg = uint16(term.AttrBold)
+			if tree.focused {
+				fg = uint16(term.AttrReverse)
+			}
+		}
+		for _, ch := range text {
+			if x >= w {
+				break
+			}
+			canvas.Draw(x, y, ch, fg, 0)
+			x++
+		}
+	}
+}
+
+func (tree *TreeLayer) toggle() {
+	if node := tree.Selected(); node != nil && !node.IsLeaf() {
+		node.SetExpanded(!node.Expanded())
+		tree.Refresh()
+	}
+}
+
+// expand expands the selected node, or
+// selects its first child if it is expanded
+func (tree *TreeLayer) expand() {
+	node := tree.Selected()
+	switch {
+	case node == nil || node.IsLeaf():
+	case node.Expanded():
+		tree.Select(tree.selected + 1)
+	default:
+		node.SetExpanded(true)
+		tree.Refresh()
+	}
+}
+
+// collapse collapses the selected node, or
+// selects its parent if it is collapsed
+func (tree *TreeLayer) collapse() {
+	rows := tree.rows()
+	if tree.selected >= len(rows) {
+		return
+	}
+	row := rows[tree.selected]
+	if !row.node.IsLeaf() && row.node.Expanded() {
+		row.node.SetExpanded(false)
+		tree.Refresh()
+	} else if row.parent >= 0 {
+		tree.Select(row.parent)
+	}
+}
+
+func (tree *TreeLayer) HandleKey(e term.Event) bool {
+	page := max(1, tree.height-1)
+	switch {
+	case e.Key == term.KeyArrowUp || e.Ch == 'k':
+		tree.Select(tree.selected - 1)
+	case e.Key == term.KeyArrowDown || e.Ch == 'j':
+		tree.Select(tree.selected + 1)
+	case e.Key == term.KeyPgup:
+		tree.Select(tree.selected - page)
+	case e.Key == term.KeyPgdn:
+		tree.Select(tree.selected + page)
+	case e.Key == term.KeyHome:
+		tree.Select(0)
+	case e.Key == term.KeyEnd:
+		tree.Select(len(tree.rows()) - 1)
+	case e.Key == term.KeyArrowRight || e.Ch == 'l':
+		tree.expand()
+	case e.Key == term.KeyArrowLeft || e.Ch == 'h':
+		tree.collapse()
+	case e.Key == term.KeySpace:
+		tree.toggle()
+	case e.Key == term.KeyEnter:
+		if tree.onSubmit == nil {
+			tree.toggle()
+		} else if node := tree.Selected(); node != nil {
+			tree.onSubmit(node)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// clicking on the selected node toggles it
+func (tree *TreeLayer) HandleMouse(e term.Event, x, y int) bool {
+	switch e.Key {
+	case term.MouseLeft:
+		if e.Mod&term.ModMotion != 0 {
+			return true
+		}
+		if i := tree.offset + y; i == tree.selected {
+			tree.toggle()
+		} else if i < len(tree.rows()) {
+			tree.Select(i)
+		}
+	case term.MouseWheelUp:
+		tree.offset = max(0, tree.offset-3)
+		tree.Select(clamp(tree.selected, tree.offset, tree.offset+tree.height-1))
+	case term.MouseWheelDown:
+		tree.offset = clamp(tree.offset+3, 0, max(0, len(tree.rows())-tree.height))
+		tree.Select(clamp(tree.selected, tree.offset, tree.offset+tree.height-1))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package wind
+
+import (
+	term "github.com/nsf/termbox-go"
+	"testing"
+)
+
+func TestTreeGuides(t *testing.T) {
+	loads := 0
+	lazy := LazyTreeItem("lazy", func() []TreeNode {
+		loads++
+		return []TreeNode{NewTreeItem("x")}
+	})
+	root := NewTreeItem("root",
+		NewTreeItem("a", NewTreeItem("b")),
+		lazy,
+	)
+	root.SetExpanded(true)
+	root.Children()[0].SetExpanded(true)
+	tree := Tree(root)
+	if loads != 0 {
+		t.Errorf("collapsed children should not be loaded")
+	}
+	if _, h := computeDimension(tree, NewStringCanvas(10, 10)); h != 4 {
+		t.Errorf("height %d", h)
+	}
+	canvas := NewStringCanvas(10, 4)
+	tree.Render(canvas)
+	want := "▾ root    \n" +
+		"├─ ▾ a    \n" +
+		"│  └─ b   \n" +
+		"└─ ▸ lazy \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("render:\n%s", got)
+	}
+
+	pressKey(tree, term.KeyEnd, 0)
+	pressKey(tree, term.KeyArrowRight, 0)
+	pressKey(tree, term.KeyArrowRight, 0)
+	if loads != 1 || tree.Selected().Label() != "x" {
+		t.Errorf("loads %d, selected %q", loads, tree.Selected().Label())
+	}
+	pressKey(tree, term.KeyArrowLeft, 0)
+	pressKey(tree, term.KeyArrowLeft, 0)
+	if lazy.Expanded() || tree.Selected() != lazy {
+		t.Errorf("Left should go to the parent, then collapse it")
+	}
+}
+
+func TestTreeScroll(t *testing.T) {
+	var nodes []TreeNode
+	for _, label := range []string{"1", "2", "3", "4"} {
+		nodes = append(nodes, NewTreeItem(label))
+	}
+	tree := Tree(nodes...)
+	canvas := NewStringCanvas(1, 2)
+	pressKey(tree, term.KeyEnd, 0)
+	tree.Render(canvas)
+	if got := canvas.String(); got != "3\n4\n" {
+		t.Errorf("selection not in view: %q", got)
+	}
+}
+
+func TestTreeInLayout(t *testing.T) {
+	root := NewTreeItem("root", NewTreeItem("a"), NewTreeItem("b"))
+	tree := Tree(root)
+	layout := Vlayer(tree, TextLine("footer"))
+
+	canvas := NewStringCanvas(8, 4)
+	layout.Render(canvas)
+	pressKey(tree, term.KeyArrowRight, 0)
+	canvas = NewStringCanvas(8, 4)
+	layout.Render(canvas)
+	want := "▾ root  \n" +
+		"├─ a    \n" +
+		"└─ b    \n" +
+		"footer  \n"
+	if got := canvas.String(); got != want {
+		t.Errorf("the layers around the tree should make room:\n%s", got)
+	}
+
+	root.Children()[0].(*TreeItem).children = []TreeNode{NewTreeItem("c")}
+	root.Children()[0].SetExpanded(true)
+	if _, h := computeDimension(tree, NewStringCanvas(8, 8)); h != 3 {
+		t.Errorf("changes outside the tree should wait for Refresh, height %d", h)
+	}
+	if _, h := computeDimension(tree.Refresh(), NewStringCanvas(8, 8)); h != 4 {
+		t.Errorf("height after Refresh %d", h)
+	}
+}
@@ -2,6 +2,7 @@ package wind
 
 import (
 	"github.com/nvlled/wind/size"
+	"reflect"
 )
 
 func computeDimension(layer Layer, canvas Canvas) (int, int) {
@@ -43,3 +44,16 @@ func clamp(val, min, max int) int {
 	}
 	return val
 }
+
+// sameLayer is == that does not panic
+// on layers of uncomparable types, like RenderLayer.
+func sameLayer(a, b Layer) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -3,6 +3,9 @@ package wind
 import (
 	"fmt"
 	"github.com/nvlled/wind/size"
+	"sort"
+	"strconv"
+	"sync"
 )
 
 // TODO: Rename Vlayer to Ylayer, Hlayer to Xlayer
@@ -33,7 +36,8 @@ func wrapNil(layer Layer) Layer {
 
 func (fn Defer) Width() size.T        { return wrapNil(fn()).Width() }
 func (fn Defer) Height() size.T       { return wrapNil(fn()).Height() }
-func (fn Defer) Render(canvas Canvas) { wrapNil(fn()).Render(canvas) }
+func (fn Defer) Render(canvas Canvas) { RenderChild(wrapNil(fn()), canvas) }
+func (fn Defer) Children() []Layer    { return []Layer{fn()} }
 
 type listLayer interface {
 	Layer
@@ -48,18 +52,17 @@ type cacheLayer struct {
 	height       size.T
 	allocWidths  []int
 	allocHeights []int
+	allocW       int
+	allocH       int
 	sizeCached   bool
 	allocCached  bool
 }
 
-func (layer *cacheLayer) clear() {
+// invalidate drops the cached sizes of this layer only,
+// for when a layer in it changes size, see ClearCache
+func (layer *cacheLayer) invalidate() {
 	layer.sizeCached = false
 	layer.allocCached = false
-	for _, subLayer := range layer.subLayer.Elements() {
-		if l, ok := subLayer.(*cacheLayer); ok {
-			l.clear()
-		}
-	}
 }
 
 func (layer *cacheLayer) Elements() []Layer {
@@ -84,11 +87,12 @@ func (layer *cacheLayer) cacheSize() {
 }
 
 func (layer *cacheLayer) cacheAlloc(w, h int) ([]int, []int) {
-	if !layer.allocCached {
+	if !layer.allocCached || w != layer.allocW || h != layer.allocH {
 		subLayer := layer.subLayer
 		widths, heights := subLayer.AllocSizes(w, h)
 		layer.allocWidths = widths
 		layer.allocHeights = heights
+		layer.allocW, layer.allocH = w, h
 		layer.allocCached = true
 	}
 	return layer.allocWidths, layer.allocHeights
@@ -104,11 +108,66 @@ func (layer *cacheLayer) Height() size.T {
 	return layer.height
 }
 
+// Children skips the list layer, which is
+// rendered as part of the cache layer
+func (layer *cacheLayer) Children() []Layer { return layer.subLayer.Elements() }
+
 func (layer *cacheLayer) Render(canvas Canvas) {
 	subLayer := layer.subLayer
 	w, h := computeDimension(layer, canvas)
 	widths, heights := layer.cacheAlloc(w, h)
-	subLayer.RenderAlloc(canvas, widths, heights)
+	subLayer.RenderAlloc(&cacheCanvas{canvas, layer}, widths, heights)
+}
+
+// cacheCanvas lets the layers in a cache layer find it, and the
+// cache layers around it, for invalidating their sizes
+type cacheCanvas struct {
+	Canvas
+	cache *cacheLayer
+}
+
+func (canvas *cacheCanvas) unwrap() Canvas { return canvas.Canvas }
+
+func (canvas *cacheCanvas) New(x, y, width, height int) Canvas {
+	return &cacheCanvas{canvas.Canvas.New(x, y, width, height), canvas.cache}
+}
+
+// layoutRef remembers the cache layers around a layer at its
+// last render, for layers that change their own size
+type layoutRef struct {
+	mu        sync.Mutex
+	caches    []*cacheLayer
+	scheduler *Scheduler
+}
+
+func (ref *layoutRef) record(canvas Canvas) {
+	var caches []*cacheLayer
+	for c := canvas; c != nil; {
+		if cc, ok := c.(*cacheCanvas); ok {
+			caches = append(caches, cc.cache)
+		}
+		wrapper, ok := c.(canvasWrapper)
+		if !ok {
+			break
+		}
+		c = wrapper.unwrap()
+	}
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+	ref.caches = caches
+	if c, ok := findCanvas[*schedulerCanvas](canvas); ok {
+		ref.scheduler = c.scheduler
+	}
+}
+
+// clear clears the caches at once, for changes
+// made on the goroutine that renders
+func (ref *layoutRef) clear() {
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+	for _, cache := range ref.caches {
+		cache.invalidate()
+	}
 }
 
 func renderListLayer(layer listLayer, canvas Canvas) {
@@ -146,13 +205,14 @@ func (layer *hLayer) RenderAlloc(canvas Canvas, widths, heights []int) {
 		h := heights[i]
 
 		subCanvas := canvas.New(x, y, w, h)
-		elem.Render(subCanvas)
+		RenderChild(elem, subCanvas)
 
 		x = x + w
 	}
 }
 
 func (layer *hLayer) Render(canvas Canvas) { renderListLayer(layer, canvas) }
+func (layer *hLayer) Children() []Layer    { return layer.elements }
 
 type vLayer struct{ elements []Layer }
 
@@ -170,10 +230,22 @@ func (layer *vLayer) Height() size.T {
 
 func (layer *vLayer) AllocSizes(w, h int) ([]int, []int) {
 	widths := size.AllocMax(w, mapWidths(layer.elements))
-	heights := size.AllocFair(h, mapHeights(layer.elements))
+	heights := size.AllocFair(h, layer.heightsAt(widths))
 	return widths, heights
 }
 
+// heightsAt has the heights of the elements, with the height at
+// its width for each element that is a HeightForWidth
+func (layer *vLayer) heightsAt(widths []int) []size.T {
+	heights := mapHeights(layer.elements)
+	for i, elem := range layer.elements {
+		if h, ok := heightFor(elem, widths[i]); ok {
+			heights[i] = size.Const(h)
+		}
+	}
+	return heights
+}
+
 func (layer *vLayer) RenderAlloc(canvas Canvas, widths, heights []int) {
 	x, y := 0, 0
 	for i, elem := range layer.elements {
@@ -181,13 +253,14 @@ func (layer *vLayer) RenderAlloc(canvas Canvas, widths, heights []int) {
 		h := heights[i]
 
 		subCanvas := canvas.New(x, y, w, h)
-		elem.Render(subCanvas)
+		RenderChild(elem, subCanvas)
 
 		y = y + h
 	}
 }
 
 func (layer *vLayer) Render(canvas Canvas) { renderListLayer(layer, canvas) }
+func (layer *vLayer) Children() []Layer    { return layer.elements }
 
 type zLayer struct{ elements []Layer }
 
@@ -216,16 +289,18 @@ func (layer *zLayer) RenderAlloc(canvas Canvas, widths, heights []int) {
 		h := heights[i]
 
 		subCanvas := canvas.New(x, y, w, h)
-		elem.Render(subCanvas)
+		RenderChild(elem, subCanvas)
 	}
 }
 
 func (layer *zLayer) Render(canvas Canvas) { renderListLayer(layer, canvas) }
+func (layer *zLayer) Children() []Layer    { return layer.elements }
 
 type aligner struct {
-	layer Layer
-	right bool
-	down  bool
+	layer  Layer
+	right  bool
+	down   bool
+	center bool
 }
 
 // (sub)layer needs to have a size
@@ -249,6 +324,8 @@ func (aligner *aligner) Height() size.T {
 	return size.Free
 }
 
+func (aligner *aligner) Children() []Layer { return []Layer{aligner.layer} }
+
 func (aligner *aligner) Render(canvas Canvas) {
 	x, y := 0, 0
 	layer := aligner.layer
@@ -260,9 +337,13 @@ func (aligner *aligner) Render(canvas Canvas) {
 	if aligner.down {
 		y = canvas.Height() - h
 	}
+	if aligner.center {
+		x = (canvas.Width() - w) / 2
+		y = (canvas.Height() - h) / 2
+	}
 
 	canvas = canvas.New(x, y, w, h)
-	layer.Render(canvas)
+	RenderChild(layer, canvas)
 }
 
 type constrainer struct {
@@ -285,8 +366,17 @@ func (c *constrainer) Height() size.T {
 	return c.height
 }
 
+func (c *constrainer) Children() []Layer { return []Layer{c.layer} }
+
+func (c *constrainer) HeightFor(width int) (int, bool) {
+	if c.height != nil {
+		return 0, false
+	}
+	return heightFor(c.layer, width)
+}
+
 func (c *constrainer) Render(canvas Canvas) {
-	c.layer.Render(canvas)
+	RenderChild(c.layer, canvas)
 }
 
 func (_ *constrainer) SetSize(w, h int) SizedLayer {
@@ -359,11 +449,19 @@ func (wrap *Wrapper) Height() size.T {
 	return wrap.layer.Height()
 }
 
+func (wrap *Wrapper) Children() []Layer {
+	return []Layer{wrap.layer}
+}
+
+func (wrap *Wrapper) HeightFor(width int) (int, bool) {
+	return heightFor(wrap.layer, width)
+}
+
 func (wrap *Wrapper) Render(canvas Canvas) {
 	if wrap.renderer != nil {
 		wrap.renderer(canvas)
 	} else {
-		wrap.layer.Render(canvas)
+		RenderChild(wrap.layer, canvas)
 	}
 }
 
@@ -381,6 +479,15 @@ func (bLayer *borderLayer) Height() size.T {
 	return bLayer.layer.Height().Add(size.Const(2))
 }
 
+func (bLayer *borderLayer) Children() []Layer {
+	return []Layer{bLayer.layer}
+}
+
+func (bLayer *borderLayer) HeightFor(width int) (int, bool) {
+	h, ok := heightFor(bLayer.layer, max(0, width-2))
+	return h + 2, ok
+}
+
 func (bLayer *borderLayer) Render(canvas Canvas) {
 	for x := 0; x < canvas.Width(); x++ {
 		canvas.Draw(x, 0, bLayer.chX, 0, 0)
@@ -391,7 +498,7 @@ func (bLayer *borderLayer) Render(canvas Canvas) {
 		canvas.Draw(canvas.Width()-1, y, bLayer.chY, 0, 0)
 	}
 	canvas = canvas.New(1, 1, canvas.Width()-2, canvas.Height()-2)
-	bLayer.layer.Render(canvas)
+	RenderChild(bLayer.layer, canvas)
 }
 
 // ref must not be a subLayer
@@ -417,15 +524,24 @@ func (s *syncer) Height() size.T {
 	return s.layer.Height()
 }
 
+// Children does not have ref, which is somewhere else in the tree
+func (s *syncer) Children() []Layer {
+	return []Layer{s.layer}
+}
+
 func (s *syncer) Render(canvas Canvas) {
-	s.layer.Render(canvas)
+	RenderChild(s.layer, canvas)
 }
 
 type tabLayer struct {
 	elements      []Layer
+	titles        []string
 	namedElements map[string]Layer
+	names         []string // in the order they were given
 	showName      string
-	showIndex     int
+	// the shown tab, also for a shown name, as
+	// layers like a RenderLayer cannot be compared
+	showIndex int
 }
 
 func (tab *tabLayer) Width() size.T {
@@ -441,7 +557,7 @@ func (tab *tabLayer) Render(canvas Canvas) {
 	index := tab.showIndex
 	if name != "" {
 		if elem, ok := tab.namedElements[name]; ok {
-			elem.Render(canvas)
+			RenderChild(elem, canvas)
 		} else {
 			canvas.Clear()
 			canvas.DrawText(0, 0, "element not found: "+name, 0, 0)
@@ -449,7 +565,7 @@ func (tab *tabLayer) Render(canvas Canvas) {
 	} else if index >= 0 {
 		if index < len(tab.elements) {
 			elem := tab.elements[index]
-			elem.Render(canvas)
+			RenderChild(elem, canvas)
 		} else {
 			canvas.Clear()
 			canvas.DrawText(0, 0, fmt.Sprintf("invalid index: %d", index), 0, 0)
@@ -459,6 +575,40 @@ func (tab *tabLayer) Render(canvas Canvas) {
 	}
 }
 
+// Children returns the tabs, then the named layers that are not tabs
+func (tab *tabLayer) Children() []Layer {
+	layers := tab.Elements()
+	var names []string
+	for name := range tab.namedElements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		elem := tab.namedElements[name]
+		isTab := false
+		for _, l := range tab.elements {
+			isTab = isTab || sameLayer(l, elem)
+		}
+		if !isTab {
+			layers = append(layers, elem)
+		}
+	}
+	return layers
+}
+
+func (tab *tabLayer) Shown() []Layer { return []Layer{tab.current()} }
+
+// current returns the shown element, if any
+func (tab *tabLayer) current() Layer {
+	if tab.showName != "" {
+		return tab.namedElements[tab.showName]
+	}
+	if tab.showIndex >= 0 && tab.showIndex < len(tab.elements) {
+		return tab.elements[tab.showIndex]
+	}
+	return nil
+}
+
 func (tab *tabLayer) Elements() []Layer {
 	var elements []Layer
 	for _, elem := range tab.elements {
@@ -469,16 +619,113 @@ func (tab *tabLayer) Elements() []Layer {
 
 func (tab *tabLayer) SetElements(elements ...Layer) TabLayer {
 	tab.elements = elements
+	tab.titles = make([]string, len(elements))
+	for _, name := range tab.names {
+		tab.setTitle(name, tab.namedElements[name])
+	}
+	tab.findShown()
+	return tab
+}
+
+// AddTab adds layer as the last tab, named title.
+func (tab *tabLayer) AddTab(title string, layer Layer) TabLayer {
+	tab.elements = append(tab.elements, layer)
+	tab.titles = append(tab.titles, title)
+	tab.setName(title, layer)
+	tab.findShown()
 	return tab
 }
 
+// Name names layer, to show it with ShowName. A tab with
+// several names has the first one as its title.
 func (tab *tabLayer) Name(name string, layer Layer) Layer {
-	tab.namedElements[name] = layer
+	tab.setName(name, layer)
+	tab.setTitle(name, layer)
+	tab.findShown()
 	return layer
 }
 
+// findShown finds the index of the tab of the shown name,
+// by its title or else by its layer
+func (tab *tabLayer) findShown() {
+	if tab.showName == "" {
+		return
+	}
+	tab.showIndex = -1
+	for i, title := range tab.titles {
+		if title == tab.showName {
+			tab.showIndex = i
+			return
+		}
+	}
+	if current := tab.current(); current != nil {
+		for i, elem := range tab.elements {
+			if sameLayer(elem, current) {
+				tab.showIndex = i
+				return
+			}
+		}
+	}
+}
+
+func (tab *tabLayer) setName(name string, layer Layer) {
+	if _, ok := tab.namedElements[name]; !ok {
+		tab.names = append(tab.names, name)
+	}
+	tab.namedElements[name] = layer
+}
+
+func (tab *tabLayer) setTitle(name string, layer Layer) {
+	for i, elem := range tab.elements {
+		if tab.titles[i] == "" && sameLayer(elem, layer) {
+			tab.titles[i] = name
+		}
+	}
+}
+
+// Titles returns the names of the tabs in order.
+// Tabs without a name are numbered from 1.
+func (tab *tabLayer) Titles() []string {
+	titles := make([]string, len(tab.elements))
+	for i := range titles {
+		titles[i] = tab.titles[i]
+		if titles[i] == "" {
+			titles[i] = strconv.Itoa(i + 1)
+		}
+	}
+	return titles
+}
+
+// Index returns the index of the shown tab, or -1 if no tab
+// is shown or the shown named layer is not one of the tabs.
+func (tab *tabLayer) Index() int {
+	if tab.showIndex < len(tab.elements) {
+		return tab.showIndex
+	}
+	return -1
+}
+
+// Next shows the tab after the shown one, going around.
+func (tab *tabLayer) Next() TabLayer { return tab.step(1) }
+
+// Prev shows the tab before the shown one, going around.
+func (tab *tabLayer) Prev() TabLayer { return tab.step(-1) }
+
+func (tab *tabLayer) step(step int) TabLayer {
+	n := len(tab.elements)
+	if n == 0 {
+		return tab
+	}
+	i := tab.Index()
+	if i < 0 && step < 0 {
+		i = 0
+	}
+	return tab.ShowIndex(((i+step)%n + n) % n)
+}
+
 func (tab *tabLayer) ShowName(name string) TabLayer {
 	tab.showName = name
+	tab.findShown()
 	return tab
 }
 
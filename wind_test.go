@@ -122,6 +122,34 @@ func TestCaching(t *testing.T) {
 	println(canvas.String())
 }
 
+func TestClearCacheThroughWrappers(t *testing.T) {
+	inBorder := Vlayer(stars)
+	inColor := Hlayer(spikes)
+	inSize := Zlayer(doughs)
+	hidden := Vlayer(stars)
+	tab := Tab().SetElements(SetColor(1, 0, inColor), Border('-', '|', hidden))
+	root := Vlayer(Border('-', '|', inBorder), tab, AlignRight(Size(5, 5, inSize)))
+
+	root.Render(NewStringCanvas(30, 30))
+	caches := []Layer{inBorder, inColor, inSize}
+	for _, l := range append(caches, hidden) {
+		l.Width()
+	}
+	ClearCache(root)
+	for i, l := range append(caches, hidden) {
+		if l.(*cacheLayer).sizeCached {
+			t.Errorf("cache %d should be cleared", i)
+		}
+	}
+
+	if shown := tab.(Switcher).Shown(); len(shown) != 1 {
+		t.Errorf("the tab shows one layer: %v", shown)
+	}
+	if all := tab.(Container).Children(); len(all) != 2 {
+		t.Errorf("the tab has two layers: %v", all)
+	}
+}
+
 func BenchmarkUncached(b *testing.B) {
 	hlayer := func(elms ...Layer) Layer { return &hLayer{elms} }
 	vlayer := func(elms ...Layer) Layer { return &vLayer{elms} }